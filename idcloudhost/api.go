@@ -0,0 +1,91 @@
+package idcloudhost
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// apiError is returned by apiRequest when the API answers with a non-2xx
+// status code.
+type apiError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *apiError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("API returned status %d", e.StatusCode)
+	}
+	return fmt.Sprintf("API returned status %d: %s", e.StatusCode, e.Message)
+}
+
+// apiRequest calls an API endpoint that the client library does not cover.
+// path is relative to the versioned API root, e.g. "jkt01/user-resource/vm/start".
+// form is sent url-encoded like the library does, and the JSON response is
+// decoded into out unless it is nil.
+func (cfg *Config) apiRequest(ctx context.Context, method string, path string, form url.Values, out interface{}) error {
+	if cfg.httpClient == nil {
+		return fmt.Errorf("provider is not configured")
+	}
+	apiURL := cfg.APIURL
+	if apiURL == "" {
+		apiURL = defaultAPIURL
+	}
+	endpoint := fmt.Sprintf("%s/v1/%s", strings.TrimSuffix(apiURL, "/"), path)
+
+	var body io.Reader
+	if form != nil {
+		if method == http.MethodGet {
+			endpoint += "?" + form.Encode()
+		} else {
+			body = strings.NewReader(form.Encode())
+		}
+	}
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("apikey", cfg.AuthToken)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+
+	resp, err := cfg.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		var errBody struct {
+			Message string `json:"message"`
+		}
+		if json.Unmarshal(respBody, &errBody) != nil || errBody.Message == "" {
+			errBody.Message = strings.TrimSpace(string(respBody))
+		}
+		return &apiError{StatusCode: resp.StatusCode, Message: errBody.Message}
+	}
+	if out == nil || len(respBody) == 0 {
+		return nil
+	}
+	return json.Unmarshal(respBody, out)
+}
+
+// isNotFoundError reports whether err is an apiError for an object that does
+// not exist. The client library only returns plain errors that cannot be told
+// apart reliably, so lookups that remove resources from the state when the
+// object is gone go through apiRequest, see getVM.
+func isNotFoundError(err error) bool {
+	var apiErr *apiError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound
+}
@@ -0,0 +1,27 @@
+package idcloudhost
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestIsNotFoundError(t *testing.T) {
+	cases := []struct {
+		err  error
+		want bool
+	}{
+		{nil, false},
+		{&apiError{StatusCode: http.StatusNotFound}, true},
+		{fmt.Errorf("unable to get VM: %w", &apiError{StatusCode: http.StatusNotFound}), true},
+		{&apiError{StatusCode: http.StatusInternalServerError, Message: "upstream returned 404 not found"}, false},
+		{errors.New("404: VM not found"), false},
+		{errors.New("disk 00000000-0000-4000-8000-000000001404 not found"), false},
+	}
+	for _, tc := range cases {
+		if got := isNotFoundError(tc.err); got != tc.want {
+			t.Errorf("isNotFoundError(%v) = %t, want %t", tc.err, got, tc.want)
+		}
+	}
+}
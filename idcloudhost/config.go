@@ -0,0 +1,184 @@
+package idcloudhost
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+
+	idcloudhostAPI "github.com/bapung/idcloudhost-go-client-library/idcloudhost/api"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+const defaultAPIURL = "https://api.idcloudhost.com"
+
+// Config holds the provider block settings used to build the API client.
+type Config struct {
+	AuthToken          string
+	Region             string
+	APIURL             string
+	HTTPProxy          string
+	InsecureSkipVerify bool
+	CACertFile         string
+	RequestTimeout     time.Duration
+	MaxRetries         int
+	RetryWaitMin       time.Duration
+	RetryWaitMax       time.Duration
+	MaxRequestsPerSec  float64
+	Burst              int
+
+	// DefaultBillingAccountID is used by resources without billing_account_id.
+	// When zero, the account marked as default by the API is used.
+	DefaultBillingAccountID int
+
+	httpClient *http.Client
+
+	osImagesMu    sync.Mutex
+	osImagesCache map[string][]osImage
+
+	billingAccountMu        sync.Mutex
+	defaultBillingAccountID int
+}
+
+// loadAndValidate builds the HTTP client shared by every API client handed out
+// by Client, so that retries and rate limiting apply provider-wide.
+func (cfg *Config) loadAndValidate() error {
+	httpClient, err := cfg.newHTTPClient()
+	if err != nil {
+		return err
+	}
+	cfg.httpClient = httpClient
+	return nil
+}
+
+// Client returns a new API client for a single resource operation in region,
+// or in the provider region when it is empty.
+//
+// The library sub-clients keep the result of every call in their own fields
+// (VM.VM, Disk.Disk, FloatingIP.FloatingIP) and Disk holds the VM it is bound
+// to, so an instance must never be shared between operations that Terraform
+// may run concurrently. Only the underlying HTTP client is shared.
+func (cfg *Config) Client(region string) (*idcloudhostAPI.APIClient, error) {
+	if cfg.httpClient == nil {
+		return nil, fmt.Errorf("provider is not configured")
+	}
+	if region == "" {
+		region = cfg.Region
+	}
+	c, err := idcloudhostAPI.NewClient(cfg.AuthToken, region)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.VM.Init(cfg.httpClient, cfg.AuthToken, region); err != nil {
+		return nil, err
+	}
+	if err := c.Disk.Init(cfg.httpClient, cfg.AuthToken, region); err != nil {
+		return nil, err
+	}
+	if err := c.FloatingIP.Init(cfg.httpClient, cfg.AuthToken, region); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// resourceRegion returns the region set on a resource or data source, or the
+// provider region when it is not set.
+func (cfg *Config) resourceRegion(d regionGetter) string {
+	if v, ok := d.GetOk("region"); ok {
+		return v.(string)
+	}
+	return cfg.Region
+}
+
+// regionKnown reports whether the region of a planned resource is known. An
+// unset region is computed and known to be the provider region, but
+// NewValueKnown reports it as unknown.
+func regionKnown(d *schema.ResourceDiff) bool {
+	rawConfig := d.GetRawConfig()
+	if rawConfig.IsNull() {
+		return true
+	}
+	if !rawConfig.IsKnown() {
+		return false
+	}
+	return rawConfig.GetAttr("region").IsKnown()
+}
+
+// regionGetter is implemented by both schema.ResourceData and
+// schema.ResourceDiff.
+type regionGetter interface {
+	GetOk(key string) (interface{}, bool)
+}
+
+func (cfg *Config) newHTTPClient() (*http.Client, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+
+	if cfg.HTTPProxy != "" {
+		proxyURL, err := url.Parse(cfg.HTTPProxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid http_proxy %q: %s", cfg.HTTPProxy, err)
+		}
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: cfg.InsecureSkipVerify,
+	}
+	if cfg.CACertFile != "" {
+		caCert, err := os.ReadFile(cfg.CACertFile)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read ca_cert_file: %s", err)
+		}
+		caCertPool, err := x509.SystemCertPool()
+		if err != nil || caCertPool == nil {
+			caCertPool = x509.NewCertPool()
+		}
+		if !caCertPool.AppendCertsFromPEM(caCert) {
+			return nil, fmt.Errorf("no PEM certificates found in ca_cert_file %q", cfg.CACertFile)
+		}
+		tlsConfig.RootCAs = caCertPool
+	}
+	transport.TLSClientConfig = tlsConfig
+
+	var roundTripper http.RoundTripper = transport
+	if cfg.RequestTimeout > 0 {
+		roundTripper = &timeoutTransport{
+			next:    roundTripper,
+			timeout: cfg.RequestTimeout,
+		}
+	}
+	if cfg.MaxRequestsPerSec > 0 {
+		// limit every attempt, retries included, as that is what the API counts
+		roundTripper = &rateLimitTransport{
+			next:    roundTripper,
+			limiter: newTokenBucket(cfg.MaxRequestsPerSec, cfg.Burst),
+		}
+	}
+	if cfg.APIURL != "" && cfg.APIURL != defaultAPIURL {
+		apiURL, err := url.Parse(cfg.APIURL)
+		if err != nil || apiURL.Scheme == "" || apiURL.Host == "" {
+			return nil, fmt.Errorf("invalid api_url %q", cfg.APIURL)
+		}
+		roundTripper = &endpointTransport{
+			endpoint: apiURL,
+			next:     roundTripper,
+		}
+	}
+
+	if cfg.MaxRetries > 0 {
+		roundTripper = &retryTransport{
+			next:         roundTripper,
+			maxRetries:   cfg.MaxRetries,
+			retryWaitMin: cfg.RetryWaitMin,
+			retryWaitMax: cfg.RetryWaitMax,
+		}
+	}
+
+	return &http.Client{
+		Transport: roundTripper,
+	}, nil
+}
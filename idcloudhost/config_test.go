@@ -0,0 +1,270 @@
+package idcloudhost
+
+import (
+	"context"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	idcloudhostVM "github.com/bapung/idcloudhost-go-client-library/idcloudhost/vm"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// TestConfigClient_concurrentOperations runs reads of many VMs and their disks
+// in parallel, the way Terraform does with -parallelism, and checks that no
+// operation observes another one's state. Run it with -race.
+func TestConfigClient_concurrentOperations(t *testing.T) {
+	api := newFakeAPI(t)
+	cfg := api.config()
+
+	var vms []idcloudhostVM.VM
+	for i := 0; i < 10; i++ {
+		vms = append(vms, api.addVM(t.Name()+"-"+string(rune('a'+i))))
+	}
+
+	var wg sync.WaitGroup
+	for _, vm := range vms {
+		vm := vm
+		for i := 0; i < 5; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				c, err := cfg.Client("")
+				if err != nil {
+					t.Errorf("unable to get client: %s", err)
+					return
+				}
+				if err := c.VM.Get(vm.UUID); err != nil {
+					t.Errorf("unable to get VM %s: %s", vm.UUID, err)
+					return
+				}
+				if c.VM.VM.UUID != vm.UUID || c.VM.VM.Name != vm.Name {
+					t.Errorf("got VM %s (%s), want %s (%s)", c.VM.VM.UUID, c.VM.VM.Name, vm.UUID, vm.Name)
+				}
+				c.Disk.Bind(vm.UUID)
+				diskUUID := vm.Storage[0].UUID
+				if err := c.Disk.Get(diskUUID, &c.VM.VM.Storage); err != nil {
+					t.Errorf("unable to get disk %s of VM %s: %s", diskUUID, vm.UUID, err)
+					return
+				}
+				if c.Disk.Disk.UUID != diskUUID {
+					t.Errorf("got disk %s, want %s", c.Disk.Disk.UUID, diskUUID)
+				}
+			}()
+		}
+	}
+	wg.Wait()
+}
+
+func TestResourceVirtualMachineRead_concurrent(t *testing.T) {
+	api := newFakeAPI(t)
+	cfg := api.config()
+
+	var vms []idcloudhostVM.VM
+	var resourceData []*schema.ResourceData
+	for i := 0; i < 10; i++ {
+		vm := api.addVM(t.Name() + "-" + string(rune('a'+i)))
+		d := schema.TestResourceDataRaw(t, resourceVirtualMachine().Schema, map[string]interface{}{})
+		d.SetId(vm.UUID)
+		vms = append(vms, vm)
+		resourceData = append(resourceData, d)
+	}
+
+	var wg sync.WaitGroup
+	for i := range vms {
+		wg.Add(1)
+		go func(vm idcloudhostVM.VM, d *schema.ResourceData) {
+			defer wg.Done()
+			if diags := resourceVirtualMachineRead(context.Background(), d, cfg); diags.HasError() {
+				t.Errorf("unable to read VM %s: %v", vm.UUID, diags)
+				return
+			}
+			if got := d.Get("uuid").(string); got != vm.UUID {
+				t.Errorf("read VM %s into state of %s", got, vm.UUID)
+			}
+			if got := d.Get("name").(string); got != vm.Name {
+				t.Errorf("got name %q for VM %s, want %q", got, vm.UUID, vm.Name)
+			}
+		}(vms[i], resourceData[i])
+	}
+	wg.Wait()
+}
+
+// baseTransport returns the http.Transport at the bottom of the transport
+// chain built by newHTTPClient.
+func baseTransport(t *testing.T, httpClient *http.Client) *http.Transport {
+	t.Helper()
+	roundTripper := httpClient.Transport
+	for {
+		switch transport := roundTripper.(type) {
+		case *http.Transport:
+			return transport
+		case *retryTransport:
+			roundTripper = transport.next
+		case *endpointTransport:
+			roundTripper = transport.next
+		case *rateLimitTransport:
+			roundTripper = transport.next
+		case *timeoutTransport:
+			roundTripper = transport.next
+		default:
+			t.Fatalf("unexpected transport %T", roundTripper)
+		}
+	}
+}
+
+func TestConfigNewHTTPClient_httpProxy(t *testing.T) {
+	cfg := &Config{HTTPProxy: "http://proxy.example.com:3128"}
+	httpClient, err := cfg.newHTTPClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	req, _ := http.NewRequest(http.MethodGet, defaultAPIURL+"/v1/jkt01/user-resource/vm/list", nil)
+	proxyURL, err := baseTransport(t, httpClient).Proxy(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if proxyURL == nil || proxyURL.String() != cfg.HTTPProxy {
+		t.Errorf("got proxy %v, want %s", proxyURL, cfg.HTTPProxy)
+	}
+
+	cfg = &Config{HTTPProxy: "://proxy"}
+	if _, err := cfg.newHTTPClient(); err == nil || !strings.Contains(err.Error(), "invalid http_proxy") {
+		t.Errorf("got %v for an invalid http_proxy, want an error", err)
+	}
+}
+
+func TestConfigNewHTTPClient_caCertFile(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	t.Cleanup(server.Close)
+	dir := t.TempDir()
+	caCertFile := filepath.Join(dir, "ca.pem")
+	caCert := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := os.WriteFile(caCertFile, caCert, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	// the test server certificate is not trusted without ca_cert_file
+	httpClient, err := (&Config{}).newHTTPClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp, err := httpClient.Get(server.URL); err == nil {
+		resp.Body.Close()
+		t.Fatal("request to the test server succeeded without its CA certificate")
+	}
+
+	httpClient, err = (&Config{CACertFile: caCertFile}).newHTTPClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if baseTransport(t, httpClient).TLSClientConfig.RootCAs == nil {
+		t.Error("ca_cert_file is not used as root CAs")
+	}
+	resp, err := httpClient.Get(server.URL)
+	if err != nil {
+		t.Fatalf("request with ca_cert_file failed: %s", err)
+	}
+	resp.Body.Close()
+
+	invalidFile := filepath.Join(dir, "invalid.pem")
+	if err := os.WriteFile(invalidFile, []byte("not a certificate"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		caCertFile string
+		wantErr    string
+	}{
+		{filepath.Join(dir, "missing.pem"), "unable to read ca_cert_file"},
+		{dir, "unable to read ca_cert_file"},
+		{invalidFile, "no PEM certificates found"},
+	}
+	for _, tc := range cases {
+		_, err := (&Config{CACertFile: tc.caCertFile}).newHTTPClient()
+		if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+			t.Errorf("ca_cert_file %s: got %v, want an error containing %q", tc.caCertFile, err, tc.wantErr)
+		}
+	}
+}
+
+func TestConfigNewHTTPClient_insecureSkipVerify(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	t.Cleanup(server.Close)
+
+	httpClient, err := (&Config{InsecureSkipVerify: true}).newHTTPClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !baseTransport(t, httpClient).TLSClientConfig.InsecureSkipVerify {
+		t.Error("insecure_skip_verify is not set on the TLS config")
+	}
+	resp, err := httpClient.Get(server.URL)
+	if err != nil {
+		t.Fatalf("request with insecure_skip_verify failed: %s", err)
+	}
+	resp.Body.Close()
+
+	httpClient, err = (&Config{}).newHTTPClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if baseTransport(t, httpClient).TLSClientConfig.InsecureSkipVerify {
+		t.Error("certificates are not verified by default")
+	}
+}
+
+func TestConfigNewHTTPClient_apiURL(t *testing.T) {
+	var gotURL, gotHost string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotURL, gotHost = r.URL.String(), r.Host
+		w.Write([]byte("ok"))
+	}))
+	t.Cleanup(server.Close)
+	serverURL, _ := url.Parse(server.URL)
+
+	httpClient, err := (&Config{APIURL: server.URL + "/idcloudhost/"}).newHTTPClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := httpClient.Transport.(*endpointTransport); !ok {
+		t.Fatalf("got transport %T, want requests rewritten by endpointTransport", httpClient.Transport)
+	}
+	resp, err := httpClient.Get(defaultAPIURL + "/v1/jkt01/user-resource/vm?uuid=abc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if want := "/idcloudhost/v1/jkt01/user-resource/vm?uuid=abc"; gotURL != want {
+		t.Errorf("got request to %s, want %s", gotURL, want)
+	}
+	if gotHost != serverURL.Host {
+		t.Errorf("got Host %s, want %s", gotHost, serverURL.Host)
+	}
+
+	// requests to other hosts, such as a proxy, are left alone
+	gotURL = ""
+	resp, err = httpClient.Get(server.URL + "/other")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if gotURL != "/other" {
+		t.Errorf("got request to %s, want /other", gotURL)
+	}
+
+	for _, apiURL := range []string{"api.example.com", "://api.example.com"} {
+		if _, err := (&Config{APIURL: apiURL}).newHTTPClient(); err == nil || !strings.Contains(err.Error(), "invalid api_url") {
+			t.Errorf("api_url %q: got %v, want an error", apiURL, err)
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package idcloudhost
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// billingAccount is a billing account of the user owning the auth token.
+type billingAccount struct {
+	ID        int    `json:"id"`
+	Name      string `json:"display_name"`
+	IsDefault bool   `json:"is_default"`
+}
+
+func dataSourceBillingAccountsRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	cfg := m.(*Config)
+
+	accounts, err := listBillingAccounts(ctx, cfg)
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Unable to list billing accounts",
+			Detail:   fmt.Sprint(err),
+		})
+		return diags
+	}
+
+	var ids []string
+	defaultID := 0
+	accountList := []map[string]interface{}{}
+	for _, account := range accounts {
+		ids = append(ids, strconv.Itoa(account.ID))
+		if account.IsDefault {
+			defaultID = account.ID
+		}
+		accountList = append(accountList, map[string]interface{}{
+			"id":         account.ID,
+			"is_default": account.IsDefault,
+			"name":       account.Name,
+		})
+	}
+
+	if err := d.Set("billing_accounts", accountList); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("default_id", defaultID); err != nil {
+		return diag.FromErr(err)
+	}
+	d.SetId(strings.Join(ids, ","))
+	return diags
+}
+
+// listBillingAccounts returns the billing accounts of the user, sorted by ID.
+func listBillingAccounts(ctx context.Context, cfg *Config) ([]billingAccount, error) {
+	var accounts []billingAccount
+	if err := cfg.apiRequest(ctx, http.MethodGet, "payment/billing_account/list", nil, &accounts); err != nil {
+		return nil, err
+	}
+	sort.Slice(accounts, func(i, j int) bool {
+		return accounts[i].ID < accounts[j].ID
+	})
+	return accounts, nil
+}
+
+// billingAccountID returns the billing_account_id set on the resource, else
+// the provider default_billing_account_id, else the account the API marks as
+// default. The API default is looked up once per provider.
+func (cfg *Config) billingAccountID(ctx context.Context, d *schema.ResourceData) (int, error) {
+	if v, ok := d.GetOk("billing_account_id"); ok {
+		return v.(int), nil
+	}
+	if cfg.DefaultBillingAccountID != 0 {
+		return cfg.DefaultBillingAccountID, nil
+	}
+
+	cfg.billingAccountMu.Lock()
+	defer cfg.billingAccountMu.Unlock()
+	if cfg.defaultBillingAccountID != 0 {
+		return cfg.defaultBillingAccountID, nil
+	}
+	accounts, err := listBillingAccounts(ctx, cfg)
+	if err != nil {
+		return 0, fmt.Errorf("unable to resolve default billing account: %s", err)
+	}
+	for _, account := range accounts {
+		if account.IsDefault {
+			cfg.defaultBillingAccountID = account.ID
+			return account.ID, nil
+		}
+	}
+	return 0, fmt.Errorf("no billing account is marked as default, set billing_account_id or the provider default_billing_account_id")
+}
+
+func dataSourceBillingAccounts() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceBillingAccountsRead,
+		Schema: map[string]*schema.Schema{
+			"billing_accounts": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"id": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"is_default": {
+							Type:     schema.TypeBool,
+							Computed: true,
+						},
+						"name": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
+			"default_id": {
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
+		},
+	}
+}
@@ -0,0 +1,71 @@
+package idcloudhost
+
+import (
+	"fmt"
+	"strconv"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccIdcloudhostBillingAccountsDataSource_basic(t *testing.T) {
+	api := newFakeAPI(t)
+	dataSourceName := "data.idcloudhost_billing_accounts.test"
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: testAccProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: api.providerConfig() + `
+data "idcloudhost_billing_accounts" "test" {}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(dataSourceName, "billing_accounts.#", "2"),
+					resource.TestCheckResourceAttr(dataSourceName, "billing_accounts.0.name", "legacy"),
+					resource.TestCheckResourceAttr(dataSourceName, "billing_accounts.0.is_default", "false"),
+					resource.TestCheckResourceAttr(dataSourceName, "billing_accounts.1.is_default", "true"),
+					resource.TestCheckResourceAttr(dataSourceName, "default_id", strconv.Itoa(fakeAPIBillingAccountID)),
+				),
+			},
+		},
+	})
+}
+
+func TestAccIdcloudhostFloatingIP_defaultBillingAccount(t *testing.T) {
+	api := newFakeAPI(t)
+	resourceName := "idcloudhost_floating_ip.test"
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: testAccProviderFactories,
+		CheckDestroy:      testAccCheckFloatingIPDestroy(api),
+		Steps: []resource.TestStep{
+			{
+				Config: api.providerConfig() + `
+resource "idcloudhost_floating_ip" "test" {
+  name = "testip"
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckFloatingIPExists(api, resourceName),
+					resource.TestCheckResourceAttr(resourceName, "billing_account_id", strconv.Itoa(fakeAPIBillingAccountID)),
+				),
+			},
+			{
+				Config: fmt.Sprintf(`
+provider "idcloudhost" {
+  auth_token                 = %q
+  api_url                    = %q
+  default_billing_account_id = 1200132375
+}
+
+resource "idcloudhost_floating_ip" "other" {
+  name = "otherip"
+}
+`, fakeAPIToken, api.server.URL),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("idcloudhost_floating_ip.other", "billing_account_id", "1200132375"),
+				),
+			},
+		},
+	})
+}
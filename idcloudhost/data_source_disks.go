@@ -0,0 +1,128 @@
+package idcloudhost
+
+import (
+	"context"
+	"fmt"
+
+	idcloudhostDisk "github.com/bapung/idcloudhost-go-client-library/idcloudhost/disk"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func dataSourceDisksRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	cfg := m.(*Config)
+	c, err := cfg.Client(cfg.resourceRegion(d))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	var diags diag.Diagnostics
+	diskApi := c.Disk
+	vmApi := c.VM
+
+	vmUUID := d.Get("vm_uuid").(string)
+	diskApi.Bind(vmUUID)
+	err = vmApi.Get(vmUUID)
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Unable to get Disks from specified VM",
+			Detail:   fmt.Sprint(err),
+		})
+		return diags
+	}
+
+	var disks []idcloudhostDisk.DiskStorage
+	for _, storage := range vmApi.VM.Storage {
+		err = diskApi.Get(storage.UUID, &vmApi.VM.Storage)
+		if err != nil {
+			diags = append(diags, diag.Diagnostic{
+				Severity: diag.Error,
+				Summary:  "Unable to get Disk",
+				Detail:   fmt.Sprint(err),
+			})
+			return diags
+		}
+		disks = append(disks, *diskApi.Disk)
+	}
+
+	if err := d.Set("disks", flattenDiskStorageList(disks)); err != nil {
+		return diag.FromErr(err)
+	}
+	d.SetId(vmUUID)
+	return diags
+}
+
+func dataSourceDisks() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceDisksRead,
+		Schema: map[string]*schema.Schema{
+			"vm_uuid": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"region": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"disks": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"created_at": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"id": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"name": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"pool": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"primary": {
+							Type:     schema.TypeBool,
+							Computed: true,
+						},
+						"replica": {
+							Type:     schema.TypeList,
+							Computed: true,
+							Elem: &schema.Schema{
+								Type: schema.TypeString,
+							},
+						},
+						"shared": {
+							Type:     schema.TypeBool,
+							Computed: true,
+						},
+						"size": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"type": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"updated_at": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"user_id": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"uuid": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,55 @@
+package idcloudhost
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccIdcloudhostDisksDataSource_basic(t *testing.T) {
+	api := newFakeAPI(t)
+	dataSourceName := "data.idcloudhost_vm_disks.test"
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: testAccProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccDiskConfig(api, 22) + `
+data "idcloudhost_vm_disks" "test" {
+  vm_uuid = idcloudhost_vm_disks.test.vm_uuid
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrPair(dataSourceName, "id", "idcloudhost_vm.test", "uuid"),
+					resource.TestCheckResourceAttr(dataSourceName, "disks.#", "2"),
+					resource.TestCheckResourceAttr(dataSourceName, "disks.0.primary", "true"),
+					resource.TestCheckResourceAttr(dataSourceName, "disks.0.size", "20"),
+					resource.TestCheckResourceAttr(dataSourceName, "disks.1.primary", "false"),
+					resource.TestCheckResourceAttr(dataSourceName, "disks.1.size", "22"),
+					resource.TestCheckResourceAttrPair(dataSourceName, "disks.1.uuid", "idcloudhost_vm_disks.test", "uuid"),
+					resource.TestCheckResourceAttrSet(dataSourceName, "disks.1.pool"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccIdcloudhostDisksDataSource_unknownVM(t *testing.T) {
+	api := newFakeAPI(t)
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: testAccProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: api.providerConfig() + fmt.Sprintf(`
+data "idcloudhost_vm_disks" "test" {
+  vm_uuid = %q
+}
+`, "00000000-0000-4000-8000-000000000000"),
+				ExpectError: regexp.MustCompile("Unable to get Disks from specified VM"),
+			},
+		},
+	})
+}
@@ -0,0 +1,125 @@
+package idcloudhost
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func dataSourceFloatingIPRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	cfg := m.(*Config)
+	var diags diag.Diagnostics
+
+	var fip *floatingIP
+	if address, ok := d.GetOk("address"); ok {
+		var err error
+		fip, err = getFloatingIP(ctx, cfg, cfg.resourceRegion(d), address.(string))
+		if err != nil {
+			diags = append(diags, diag.Diagnostic{
+				Severity: diag.Error,
+				Summary:  "Unable to get Floating IP",
+				Detail:   fmt.Sprint(err),
+			})
+			return diags
+		}
+	} else {
+		name := d.Get("name").(string)
+		fipList, err := listFloatingIPs(ctx, cfg, cfg.resourceRegion(d))
+		if err != nil {
+			diags = append(diags, diag.Diagnostic{
+				Severity: diag.Error,
+				Summary:  "Unable to list Floating IPs",
+				Detail:   fmt.Sprint(err),
+			})
+			return diags
+		}
+		var matches []floatingIP
+		for _, f := range fipList {
+			if f.Name == name {
+				matches = append(matches, f)
+			}
+		}
+		if len(matches) != 1 {
+			diags = append(diags, diag.Diagnostic{
+				Severity: diag.Error,
+				Summary:  "Unable to get Floating IP",
+				Detail:   fmt.Sprintf("expected exactly one Floating IP named %q, found %d", name, len(matches)),
+			})
+			return diags
+		}
+		fip = &matches[0]
+	}
+
+	d.SetId(fip.Address)
+	if err := setFloatingIP(d, fip); err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Unable to get Floating IP",
+			Detail:   fmt.Sprint(err),
+		})
+		return diags
+	}
+	return diags
+}
+
+func dataSourceFloatingIP() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceFloatingIPRead,
+		Schema: map[string]*schema.Schema{
+			"id": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"address": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				ExactlyOneOf: []string{"address", "name"},
+			},
+			"user_id": {
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
+			"billing_account_id": {
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
+			"type": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"network_id": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"name": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				ExactlyOneOf: []string{"address", "name"},
+			},
+			"enabled": {
+				Type:     schema.TypeBool,
+				Computed: true,
+			},
+			"created_at": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"updated_at": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"region": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"assigned_to": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+		},
+	}
+}
@@ -0,0 +1,49 @@
+package idcloudhost
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccIdcloudhostFloatingIPDataSource_basic(t *testing.T) {
+	api := newFakeAPI(t)
+	vm := api.addVM("web-1")
+	fip := api.addFloatingIP("prod-web", fakeAPIBillingAccountID, vm.UUID)
+	api.addFloatingIP("duplicate", fakeAPIBillingAccountID, "")
+	api.addFloatingIP("duplicate", fakeAPIBillingAccountID, "")
+	dataSourceName := "data.idcloudhost_floating_ip.test"
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: testAccProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccFloatingIPDataSourceConfig(api, "address", fip.Address),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(dataSourceName, "name", "prod-web"),
+					resource.TestCheckResourceAttr(dataSourceName, "assigned_to", vm.UUID),
+				),
+			},
+			{
+				Config: testAccFloatingIPDataSourceConfig(api, "name", "prod-web"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(dataSourceName, "address", fip.Address),
+				),
+			},
+			{
+				Config:      testAccFloatingIPDataSourceConfig(api, "name", "duplicate"),
+				ExpectError: regexp.MustCompile("expected exactly one Floating IP"),
+			},
+		},
+	})
+}
+
+func testAccFloatingIPDataSourceConfig(api *fakeAPI, key string, value string) string {
+	return api.providerConfig() + fmt.Sprintf(`
+data "idcloudhost_floating_ip" "test" {
+  %s = %q
+}
+`, key, value)
+}
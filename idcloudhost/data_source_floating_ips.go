@@ -0,0 +1,180 @@
+package idcloudhost
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+
+	idcloudhostFloatingIP "github.com/bapung/idcloudhost-go-client-library/idcloudhost/floatingip"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func dataSourceFloatingIPsRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	cfg := m.(*Config)
+
+	fipList, err := listFloatingIPs(ctx, cfg, cfg.resourceRegion(d))
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Unable to list Floating IPs",
+			Detail:   fmt.Sprint(err),
+		})
+		return diags
+	}
+
+	var nameRegex *regexp.Regexp
+	if v, ok := d.GetOk("name_regex"); ok {
+		nameRegex = regexp.MustCompile(v.(string))
+	}
+	billingAccountId := d.Get("billing_account_id").(int)
+	rawConfig := d.GetRawConfig()
+	assignedFilter := !rawConfig.IsNull() && !rawConfig.GetAttr("assigned").IsNull()
+	assigned := d.Get("assigned").(bool)
+
+	var addresses []string
+	fipMapList := []map[string]interface{}{}
+	for _, fip := range fipList {
+		if nameRegex != nil && !nameRegex.MatchString(fip.Name) {
+			continue
+		}
+		if billingAccountId != 0 && fip.BillingAccountID != billingAccountId {
+			continue
+		}
+		if assignedFilter && (fip.AssignedTo != "") != assigned {
+			continue
+		}
+		addresses = append(addresses, fip.Address)
+		fipMapList = append(fipMapList, flattenFloatingIP(&fip))
+	}
+
+	if err := d.Set("floating_ips", fipMapList); err != nil {
+		return diag.FromErr(err)
+	}
+	hash := sha1.Sum([]byte(strings.Join(addresses, ",")))
+	d.SetId(hex.EncodeToString(hash[:]))
+	return diags
+}
+
+// floatingIP is a floating IP as returned by the API. The client library does
+// not decode the billing account the address is charged to, which differs
+// from the user owning it.
+type floatingIP struct {
+	idcloudhostFloatingIP.FloatingIP
+	BillingAccountID int `json:"billing_account_id"`
+}
+
+// listFloatingIPs returns every floating IP of the account in region, sorted
+// by address.
+func listFloatingIPs(ctx context.Context, cfg *Config, region string) ([]floatingIP, error) {
+	var fipList []floatingIP
+	path := fmt.Sprintf("%s/network/ip_addresses", region)
+	if err := cfg.apiRequest(ctx, http.MethodGet, path, nil, &fipList); err != nil {
+		return nil, err
+	}
+	sort.Slice(fipList, func(i, j int) bool {
+		return fipList[i].Address < fipList[j].Address
+	})
+	return fipList, nil
+}
+
+// getFloatingIP fetches a floating IP through apiRequest rather than the
+// client library, so a missing address can be recognized with isNotFoundError.
+func getFloatingIP(ctx context.Context, cfg *Config, region string, address string) (*floatingIP, error) {
+	var fip floatingIP
+	path := fmt.Sprintf("%s/network/ip_addresses/%s", region, url.PathEscape(address))
+	if err := cfg.apiRequest(ctx, http.MethodGet, path, nil, &fip); err != nil {
+		return nil, err
+	}
+	return &fip, nil
+}
+
+func dataSourceFloatingIPs() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceFloatingIPsRead,
+		Schema: map[string]*schema.Schema{
+			"assigned": {
+				Type:     schema.TypeBool,
+				Optional: true,
+			},
+			"billing_account_id": {
+				Type:     schema.TypeInt,
+				Optional: true,
+			},
+			"name_regex": {
+				Type:     schema.TypeString,
+				Optional: true,
+				ValidateFunc: func(val interface{}, key string) (warns []string, errs []error) {
+					v := val.(string)
+					if _, err := regexp.Compile(v); err != nil {
+						errs = append(errs, fmt.Errorf("%q must be a valid regular expression, got: %s", key, err))
+					}
+					return
+				},
+			},
+			"region": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"floating_ips": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"id": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"address": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"user_id": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"billing_account_id": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"type": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"network_id": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"name": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"enabled": {
+							Type:     schema.TypeBool,
+							Computed: true,
+						},
+						"created_at": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"updated_at": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"assigned_to": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,91 @@
+package idcloudhost
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccIdcloudhostFloatingIPsDataSource_basic(t *testing.T) {
+	api := newFakeAPI(t)
+	vm := api.addVM("web-1")
+	assigned := api.addFloatingIP("prod-web", fakeAPIBillingAccountID, vm.UUID)
+	unassigned := api.addFloatingIP("prod-spare", fakeAPIBillingAccountID, "")
+	api.addFloatingIP("staging-web", 1, "")
+	dataSourceName := "data.idcloudhost_floating_ips.test"
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: testAccProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccFloatingIPsDataSourceConfig(api, ""),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(dataSourceName, "floating_ips.#", "3"),
+				),
+			},
+			{
+				Config: testAccFloatingIPsDataSourceConfig(api, `name_regex = "^prod-"`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(dataSourceName, "floating_ips.#", "2"),
+				),
+			},
+			{
+				Config: testAccFloatingIPsDataSourceConfig(api, `assigned = true`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(dataSourceName, "floating_ips.#", "1"),
+					resource.TestCheckResourceAttr(dataSourceName, "floating_ips.0.address", assigned.Address),
+					resource.TestCheckResourceAttr(dataSourceName, "floating_ips.0.assigned_to", vm.UUID),
+				),
+			},
+			{
+				Config: testAccFloatingIPsDataSourceConfig(api, fmt.Sprintf("assigned = false\nbilling_account_id = %d", fakeAPIBillingAccountID)),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(dataSourceName, "floating_ips.#", "1"),
+					resource.TestCheckResourceAttr(dataSourceName, "floating_ips.0.address", unassigned.Address),
+					resource.TestCheckResourceAttr(dataSourceName, "floating_ips.0.billing_account_id", strconv.Itoa(fakeAPIBillingAccountID)),
+					resource.TestCheckResourceAttr(dataSourceName, "floating_ips.0.user_id", strconv.Itoa(fakeAPIUserID)),
+				),
+			},
+		},
+	})
+}
+
+// TestDataSourceFloatingIPsRead_billingAccount checks that billing_account_id
+// filters on the billing account of the addresses, not on the user owning
+// them.
+func TestDataSourceFloatingIPsRead_billingAccount(t *testing.T) {
+	api := newFakeAPI(t)
+	cfg := api.config()
+	charged := api.addFloatingIP("prod-web", fakeAPIBillingAccountID, "")
+	api.addFloatingIP("staging-web", fakeAPIUserID, "")
+
+	d := dataSourceFloatingIPs().TestResourceData()
+	if err := d.Set("billing_account_id", fakeAPIBillingAccountID); err != nil {
+		t.Fatal(err)
+	}
+	if diags := dataSourceFloatingIPsRead(context.Background(), d, cfg); diags.HasError() {
+		t.Fatalf("unable to read floating IPs: %v", diags)
+	}
+	fipList := d.Get("floating_ips").([]interface{})
+	if len(fipList) != 1 {
+		t.Fatalf("got %d floating IPs, want only %s", len(fipList), charged.Address)
+	}
+	fip := fipList[0].(map[string]interface{})
+	if fip["address"] != charged.Address {
+		t.Errorf("got floating IP %s, want %s", fip["address"], charged.Address)
+	}
+	if fip["billing_account_id"] != fakeAPIBillingAccountID || fip["user_id"] != fakeAPIUserID {
+		t.Errorf("got billing_account_id %v and user_id %v, want %d and %d", fip["billing_account_id"], fip["user_id"], fakeAPIBillingAccountID, fakeAPIUserID)
+	}
+}
+
+func testAccFloatingIPsDataSourceConfig(api *fakeAPI, arguments string) string {
+	return api.providerConfig() + fmt.Sprintf(`
+data "idcloudhost_floating_ips" "test" {
+  %s
+}
+`, arguments)
+}
@@ -0,0 +1,98 @@
+package idcloudhost
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// location is an IDCloudHost data center, its slug is the region used in API
+// paths. The API does not return what a location offers, the images of a
+// region are listed by idcloudhost_os_images.
+type location struct {
+	Slug        string `json:"slug"`
+	DisplayName string `json:"display_name"`
+	Description string `json:"description"`
+	CountryCode string `json:"country_code"`
+	IsDefault   bool   `json:"is_default"`
+	IsPreferred bool   `json:"is_preferred"`
+}
+
+func dataSourceLocationsRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	cfg := m.(*Config)
+
+	var locations []location
+	if err := cfg.apiRequest(ctx, http.MethodGet, "config/locations", nil, &locations); err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Unable to list locations",
+			Detail:   fmt.Sprint(err),
+		})
+		return diags
+	}
+
+	var slugs []string
+	locationList := []map[string]interface{}{}
+	for _, l := range locations {
+		slugs = append(slugs, l.Slug)
+		locationList = append(locationList, map[string]interface{}{
+			"country_code": l.CountryCode,
+			"description":  l.Description,
+			"display_name": l.DisplayName,
+			"is_default":   l.IsDefault,
+			"is_preferred": l.IsPreferred,
+			"region":       l.Slug,
+		})
+	}
+
+	if err := d.Set("locations", locationList); err != nil {
+		return diag.FromErr(err)
+	}
+	d.SetId(strings.Join(slugs, ","))
+	return diags
+}
+
+func dataSourceLocations() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceLocationsRead,
+		Schema: map[string]*schema.Schema{
+			"locations": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"country_code": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"description": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"display_name": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"is_default": {
+							Type:     schema.TypeBool,
+							Computed: true,
+						},
+						"is_preferred": {
+							Type:     schema.TypeBool,
+							Computed: true,
+						},
+						"region": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
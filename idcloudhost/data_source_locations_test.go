@@ -0,0 +1,30 @@
+package idcloudhost
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccIdcloudhostLocationsDataSource_basic(t *testing.T) {
+	api := newFakeAPI(t)
+	dataSourceName := "data.idcloudhost_locations.test"
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: testAccProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: api.providerConfig() + `
+data "idcloudhost_locations" "test" {}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(dataSourceName, "locations.#", "2"),
+					resource.TestCheckResourceAttr(dataSourceName, "locations.0.region", "jkt01"),
+					resource.TestCheckResourceAttr(dataSourceName, "locations.0.is_default", "true"),
+					resource.TestCheckResourceAttr(dataSourceName, "locations.1.region", "sgp01"),
+					resource.TestCheckResourceAttr(dataSourceName, "locations.1.country_code", "SG"),
+				),
+			},
+		},
+	})
+}
@@ -0,0 +1,120 @@
+package idcloudhost
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func dataSourceNetworksRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	cfg := m.(*Config)
+
+	var nameRegex *regexp.Regexp
+	if v, ok := d.GetOk("name_regex"); ok {
+		nameRegex = regexp.MustCompile(v.(string))
+	}
+
+	networks, err := listNetworks(ctx, cfg, cfg.resourceRegion(d))
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Unable to list networks",
+			Detail:   fmt.Sprint(err),
+		})
+		return diags
+	}
+
+	var uuids []string
+	networkList := []map[string]interface{}{}
+	for i := range networks {
+		if nameRegex != nil && !nameRegex.MatchString(networks[i].Name) {
+			continue
+		}
+		uuids = append(uuids, networks[i].UUID)
+		networkList = append(networkList, flattenNetwork(&networks[i]))
+	}
+
+	if err := d.Set("networks", networkList); err != nil {
+		return diag.FromErr(err)
+	}
+	hash := sha1.Sum([]byte(strings.Join(uuids, ",")))
+	d.SetId(hex.EncodeToString(hash[:]))
+	return diags
+}
+
+func dataSourceNetworks() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceNetworksRead,
+		Schema: map[string]*schema.Schema{
+			"name_regex": {
+				Type:     schema.TypeString,
+				Optional: true,
+				ValidateFunc: func(val interface{}, key string) (warns []string, errs []error) {
+					v := val.(string)
+					if _, err := regexp.Compile(v); err != nil {
+						errs = append(errs, fmt.Errorf("%q must be a valid regular expression, got: %s", key, err))
+					}
+					return
+				},
+			},
+			"region": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"networks": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"cidr": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"created_at": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"default": {
+							Type:     schema.TypeBool,
+							Computed: true,
+						},
+						"name": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"type": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"updated_at": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"uuid": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"vlan_id": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"vm_uuids": {
+							Type:     schema.TypeList,
+							Computed: true,
+							Elem: &schema.Schema{
+								Type: schema.TypeString,
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
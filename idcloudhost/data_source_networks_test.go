@@ -0,0 +1,39 @@
+package idcloudhost
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccIdcloudhostNetworksDataSource_basic(t *testing.T) {
+	api := newFakeAPI(t)
+	vm := api.addVM("web-1")
+	dataSourceName := "data.idcloudhost_networks.test"
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: testAccProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: api.providerConfig() + `
+data "idcloudhost_networks" "test" {}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(dataSourceName, "networks.#", "1"),
+					resource.TestCheckResourceAttr(dataSourceName, "networks.0.name", "Default"),
+					resource.TestCheckResourceAttr(dataSourceName, "networks.0.default", "true"),
+					resource.TestCheckResourceAttr(dataSourceName, "networks.0.cidr", "10.0.0.0/24"),
+					resource.TestCheckResourceAttr(dataSourceName, "networks.0.vm_uuids.0", vm.UUID),
+				),
+			},
+			{
+				Config: api.providerConfig() + `
+data "idcloudhost_networks" "test" {
+  name_regex = "^prod-"
+}
+`,
+				Check: resource.TestCheckResourceAttr(dataSourceName, "networks.#", "0"),
+			},
+		},
+	})
+}
@@ -0,0 +1,102 @@
+package idcloudhost
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func dataSourceOSImageRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	cfg := m.(*Config)
+	region := cfg.resourceRegion(d)
+
+	images, err := cfg.osImages(ctx, region)
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Unable to list OS images",
+			Detail:   fmt.Sprint(err),
+		})
+		return diags
+	}
+
+	osName := d.Get("os_name").(string)
+	osVersion := d.Get("os_version").(string)
+	var matches []osImage
+	for _, image := range images {
+		// matched like the os_name and os_version of idcloudhost_vm
+		if !strings.EqualFold(image.OSName, osName) {
+			continue
+		}
+		if osVersion != "" && !strings.EqualFold(image.OSVersion, osVersion) {
+			continue
+		}
+		matches = append(matches, image)
+	}
+
+	if len(matches) == 0 {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "No OS image found",
+			Detail:   fmt.Sprintf("no OS image %q version %q in region %s", osName, osVersion, region),
+		})
+		return diags
+	}
+	if len(matches) > 1 && !d.Get("most_recent").(bool) {
+		var versions []string
+		for _, image := range matches {
+			versions = append(versions, image.OSVersion)
+		}
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Multiple OS images found",
+			Detail:   fmt.Sprintf("%q is available in versions %s, set os_version or most_recent = true", osName, strings.Join(versions, ", ")),
+		})
+		return diags
+	}
+
+	// images are sorted from the most recent version
+	image := matches[0]
+	d.SetId(image.OSName + "/" + image.OSVersion)
+	if err := d.Set("os_version", image.OSVersion); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("display_name", image.DisplayName); err != nil {
+		return diag.FromErr(err)
+	}
+	return diags
+}
+
+func dataSourceOSImage() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceOSImageRead,
+		Schema: map[string]*schema.Schema{
+			"display_name": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"most_recent": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+			"region": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"os_name": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"os_version": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+			},
+		},
+	}
+}
@@ -0,0 +1,159 @@
+package idcloudhost
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// osImage is an operating system image that VMs can be created from.
+type osImage struct {
+	OSName      string `json:"os_name"`
+	OSVersion   string `json:"os_version"`
+	DisplayName string `json:"display_name"`
+}
+
+func dataSourceOSImagesRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	cfg := m.(*Config)
+	region := cfg.resourceRegion(d)
+
+	images, err := cfg.osImages(ctx, region)
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Unable to list OS images",
+			Detail:   fmt.Sprint(err),
+		})
+		return diags
+	}
+
+	osName := d.Get("os_name").(string)
+	var ids []string
+	imageList := []map[string]interface{}{}
+	for _, image := range images {
+		if osName != "" && !strings.EqualFold(image.OSName, osName) {
+			continue
+		}
+		ids = append(ids, image.OSName+"/"+image.OSVersion)
+		imageList = append(imageList, flattenOSImage(&image))
+	}
+
+	if err := d.Set("images", imageList); err != nil {
+		return diag.FromErr(err)
+	}
+	hash := sha1.Sum([]byte(region + ":" + strings.Join(ids, ",")))
+	d.SetId(hex.EncodeToString(hash[:]))
+	return diags
+}
+
+func flattenOSImage(image *osImage) map[string]interface{} {
+	return map[string]interface{}{
+		"display_name": image.DisplayName,
+		"os_name":      image.OSName,
+		"os_version":   image.OSVersion,
+	}
+}
+
+// osImages returns the OS images available in region, sorted by name and then
+// from the most recent version to the oldest. The catalog of each region is
+// fetched once and shared by every resource, as VM plans validate against it.
+func (cfg *Config) osImages(ctx context.Context, region string) ([]osImage, error) {
+	cfg.osImagesMu.Lock()
+	defer cfg.osImagesMu.Unlock()
+	if images, ok := cfg.osImagesCache[region]; ok {
+		return images, nil
+	}
+
+	var images []osImage
+	path := fmt.Sprintf("%s/config/vm_images", region)
+	if err := cfg.apiRequest(ctx, http.MethodGet, path, nil, &images); err != nil {
+		return nil, err
+	}
+	sort.SliceStable(images, func(i, j int) bool {
+		if images[i].OSName != images[j].OSName {
+			return images[i].OSName < images[j].OSName
+		}
+		return compareOSVersions(images[i].OSVersion, images[j].OSVersion) > 0
+	})
+	if cfg.osImagesCache == nil {
+		cfg.osImagesCache = map[string][]osImage{}
+	}
+	cfg.osImagesCache[region] = images
+	return images, nil
+}
+
+// compareOSVersions compares dotted versions such as "7.3.1611" numerically
+// part by part, and returns -1, 0 or 1 like strings.Compare. Parts that are not
+// numbers are compared as strings.
+func compareOSVersions(a string, b string) int {
+	aParts := strings.Split(a, ".")
+	bParts := strings.Split(b, ".")
+	for i := 0; i < len(aParts) || i < len(bParts); i++ {
+		if i >= len(aParts) {
+			return -1
+		}
+		if i >= len(bParts) {
+			return 1
+		}
+		aNum, aErr := strconv.Atoi(aParts[i])
+		bNum, bErr := strconv.Atoi(bParts[i])
+		if aErr != nil || bErr != nil {
+			if c := strings.Compare(aParts[i], bParts[i]); c != 0 {
+				return c
+			}
+			continue
+		}
+		if aNum != bNum {
+			if aNum < bNum {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+func dataSourceOSImages() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceOSImagesRead,
+		Schema: map[string]*schema.Schema{
+			"os_name": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"region": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"images": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"display_name": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"os_name": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"os_version": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
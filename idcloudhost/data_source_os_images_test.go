@@ -0,0 +1,133 @@
+package idcloudhost
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccIdcloudhostOSImagesDataSource_basic(t *testing.T) {
+	api := newFakeAPI(t)
+	dataSourceName := "data.idcloudhost_os_images.test"
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: testAccProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: api.providerConfig() + `
+data "idcloudhost_os_images" "test" {}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(dataSourceName, "images.#", "5"),
+					resource.TestCheckResourceAttr(dataSourceName, "images.0.os_name", "centos"),
+					resource.TestCheckResourceAttr(dataSourceName, "images.0.os_version", "7.3.1611"),
+				),
+			},
+			{
+				Config: api.providerConfig() + `
+data "idcloudhost_os_images" "test" {
+  os_name = "ubuntu"
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(dataSourceName, "images.#", "2"),
+					resource.TestCheckResourceAttr(dataSourceName, "images.0.os_version", "20.04"),
+					resource.TestCheckResourceAttr(dataSourceName, "images.1.os_version", "18.04"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccIdcloudhostOSImageDataSource_basic(t *testing.T) {
+	api := newFakeAPI(t)
+	dataSourceName := "data.idcloudhost_os_image.test"
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: testAccProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: api.providerConfig() + `
+data "idcloudhost_os_image" "test" {
+  os_name     = "centos"
+  most_recent = true
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(dataSourceName, "os_version", "7.3.1611"),
+					resource.TestCheckResourceAttr(dataSourceName, "display_name", "CentOS 7.3"),
+				),
+			},
+			{
+				Config: api.providerConfig() + `
+data "idcloudhost_os_image" "test" {
+  os_name    = "ubuntu"
+  os_version = "18.04"
+}
+`,
+				Check: resource.TestCheckResourceAttr(dataSourceName, "id", "ubuntu/18.04"),
+			},
+			{
+				Config: api.providerConfig() + `
+data "idcloudhost_os_image" "test" {
+  os_name = "ubuntu"
+}
+`,
+				ExpectError: regexp.MustCompile("Multiple OS images found"),
+			},
+		},
+	})
+}
+
+func TestCompareOSVersions(t *testing.T) {
+	cases := []struct {
+		a, b string
+		want int
+	}{
+		{"20.04", "18.04", 1},
+		{"7.3.1611", "7.10", -1},
+		{"9", "9.1", -1},
+		{"8.2", "8.2", 0},
+		{"stream", "8", 1},
+	}
+	for _, c := range cases {
+		if got := compareOSVersions(c.a, c.b); got != c.want {
+			t.Errorf("compareOSVersions(%q, %q) = %d, want %d", c.a, c.b, got, c.want)
+		}
+	}
+}
+
+// TestDataSourceOSImagesRead_mixedCase checks that os_name matches images
+// case-insensitively, like the os_name of idcloudhost_vm.
+func TestDataSourceOSImagesRead_mixedCase(t *testing.T) {
+	api := newFakeAPI(t)
+	cfg := api.config()
+	ctx := context.Background()
+
+	d := dataSourceOSImages().TestResourceData()
+	if err := d.Set("os_name", "Ubuntu"); err != nil {
+		t.Fatal(err)
+	}
+	if diags := dataSourceOSImagesRead(ctx, d, cfg); diags.HasError() {
+		t.Fatalf("unable to read OS images: %v", diags)
+	}
+	if got := d.Get("images.#").(int); got != 2 {
+		t.Errorf("got %d images for os_name Ubuntu, want 2", got)
+	}
+
+	d = dataSourceOSImage().TestResourceData()
+	if err := d.Set("os_name", "CentOS"); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Set("most_recent", true); err != nil {
+		t.Fatal(err)
+	}
+	if diags := dataSourceOSImageRead(ctx, d, cfg); diags.HasError() {
+		t.Fatalf("unable to read OS image: %v", diags)
+	}
+	if got := d.Get("os_version").(string); got != "7.3.1611" {
+		t.Errorf("got os_version %q for os_name CentOS, want %q", got, "7.3.1611")
+	}
+}
@@ -2,32 +2,81 @@ package idcloudhost
 
 import (
 	"context"
-	"log"
-	"strconv"
-	"time"
+	"fmt"
+	"strings"
 
-	idcloudhostAPI "github.com/bapung/idcloudhost-go-client-library/idcloudhost/api"
+	idcloudhostVM "github.com/bapung/idcloudhost-go-client-library/idcloudhost/vm"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
 
 func dataSourceVirtualMachineRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-
-	c := m.(*idcloudhostAPI.APIClient)
+	cfg := m.(*Config)
+	c, err := cfg.Client(cfg.resourceRegion(d))
+	if err != nil {
+		return diag.FromErr(err)
+	}
 	var diags diag.Diagnostics
 
+	uuid := d.Get("uuid").(string)
+	name := d.Get("name").(string)
+	hostname := d.Get("hostname").(string)
+
 	vmApi := c.VM
 	if err := vmApi.ListAll(); err != nil {
-		log.Fatal(err)
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Unable to list VMs",
+			Detail:   fmt.Sprint(err),
+		})
+		return diags
 	}
-	vmList, err := adaptVMListStructToMap(&vmApi.VMList)
-	if err != nil {
-		return diag.FromErr(err)
+
+	var matches []idcloudhostVM.VM
+	for _, vm := range vmApi.VMList {
+		if uuid != "" && vm.UUID != uuid {
+			continue
+		}
+		if name != "" && vm.Name != name {
+			continue
+		}
+		if hostname != "" && vm.Hostname != hostname {
+			continue
+		}
+		matches = append(matches, vm)
 	}
-	if err := d.Set("vms", vmList); err != nil {
-		return diag.FromErr(err)
+
+	if len(matches) == 0 {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "No VM found",
+			Detail:   fmt.Sprintf("no VM matches uuid %q, name %q and hostname %q", uuid, name, hostname),
+		})
+		return diags
+	}
+	if len(matches) > 1 {
+		var uuids []string
+		for _, vm := range matches {
+			uuids = append(uuids, vm.UUID)
+		}
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Multiple VMs found",
+			Detail:   fmt.Sprintf("%d VMs match, narrow down the search or use uuid: %s", len(matches), strings.Join(uuids, ", ")),
+		})
+		return diags
+	}
+
+	vm := matches[0]
+	d.SetId(vm.UUID)
+	if err := setVmResource(d, &vm); err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Unable to get VM",
+			Detail:   fmt.Sprint(err),
+		})
+		return diags
 	}
-	d.SetId(strconv.FormatInt(time.Now().Unix(), 10))
 	return diags
 }
 
@@ -35,156 +84,166 @@ func dataSourceVirtualMachine() *schema.Resource {
 	return &schema.Resource{
 		ReadContext: dataSourceVirtualMachineRead,
 		Schema: map[string]*schema.Schema{
-			"vms": {
+			"backup": {
+				Type:     schema.TypeBool,
+				Computed: true,
+			},
+			"billing_account_id": {
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
+			"created_at": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"description": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"disks": {
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
+			"hostname": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				AtLeastOneOf: []string{"uuid", "name", "hostname"},
+			},
+			"hypervisor_id": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"id": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"mac": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"memory": {
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
+			"name": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				AtLeastOneOf: []string{"uuid", "name", "hostname"},
+			},
+			"os_name": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"os_version": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"power_state": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"private_ipv4": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"region": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"status": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"storage": {
 				Type:     schema.TypeList,
 				Computed: true,
 				Elem: &schema.Resource{
 					Schema: map[string]*schema.Schema{
-						"backup": {
-							Type:     schema.TypeBool,
-							Computed: true,
-						},
-						"billing_account_id": {
-							Type:     schema.TypeInt,
-							Computed: true,
-						},
 						"created_at": {
 							Type:     schema.TypeString,
 							Computed: true,
 						},
-						"description": {
-							Type:     schema.TypeString,
-							Computed: true,
-						},
-						"hostname": {
-							Type:     schema.TypeString,
-							Computed: true,
-						},
-						"hypervisor_id": {
-							Type:     schema.TypeString,
-							Computed: true,
-						},
 						"id": {
 							Type:     schema.TypeInt,
 							Computed: true,
 						},
-						"mac": {
-							Type:     schema.TypeString,
-							Computed: true,
-						},
-						"memory": {
-							Type:     schema.TypeInt,
-							Computed: true,
-						},
 						"name": {
 							Type:     schema.TypeString,
 							Computed: true,
 						},
-						"os_name": {
-							Type:     schema.TypeString,
-							Computed: true,
-						},
-						"os_version": {
-							Type:     schema.TypeString,
-							Computed: true,
-						},
-						"private_ipv4": {
-							Type:     schema.TypeString,
-							Computed: true,
-						},
-						"status": {
+						"pool": {
 							Type:     schema.TypeString,
 							Computed: true,
 						},
-						"storage": {
-							Type:     schema.TypeList,
+						"primary": {
+							Type:     schema.TypeBool,
 							Computed: true,
-							Elem: &schema.Resource{
-								Schema: map[string]*schema.Schema{
-									"created_at": {
-										Type:     schema.TypeString,
-										Computed: true,
-									},
-									"id": {
-										Type:     schema.TypeInt,
-										Computed: true,
-									},
-									"name": {
-										Type:     schema.TypeString,
-										Computed: true,
-									},
-									"pool": {
-										Type:     schema.TypeString,
-										Computed: true,
-									},
-									"primary": {
-										Type:     schema.TypeBool,
-										Computed: true,
-									},
-									"replica": {
-										Type:     schema.TypeList,
-										Computed: true,
-										Elem: &schema.Schema{
-											Type: schema.TypeString,
-										},
-									},
-									"shared": {
-										Type:     schema.TypeBool,
-										Computed: true,
-									},
-									"size": {
-										Type:     schema.TypeInt,
-										Computed: true,
-									},
-									"type": {
-										Type:     schema.TypeString,
-										Computed: true,
-									},
-									"updated_at": {
-										Type:     schema.TypeString,
-										Computed: true,
-									},
-									"user_id": {
-										Type:     schema.TypeInt,
-										Computed: true,
-									},
-									"uuid": {
-										Type:     schema.TypeString,
-										Computed: true,
-									},
-								},
-							},
 						},
-						"tags": {
+						"replica": {
 							Type:     schema.TypeList,
 							Computed: true,
 							Elem: &schema.Schema{
 								Type: schema.TypeString,
 							},
 						},
-						"updated_at": {
-							Type:     schema.TypeString,
+						"shared": {
+							Type:     schema.TypeBool,
 							Computed: true,
 						},
-						"user_id": {
+						"size": {
 							Type:     schema.TypeInt,
 							Computed: true,
 						},
-						"username": {
+						"type": {
 							Type:     schema.TypeString,
 							Computed: true,
 						},
-						"uuid": {
+						"updated_at": {
 							Type:     schema.TypeString,
 							Computed: true,
 						},
-						"vcpu": {
+						"user_id": {
 							Type:     schema.TypeInt,
 							Computed: true,
 						},
+						"uuid": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
 					},
 				},
 			},
+			"tags": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"updated_at": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"user_id": {
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
+			"username": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"uuid": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				AtLeastOneOf: []string{"uuid", "name", "hostname"},
+			},
+			"vcpu": {
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
 		},
 	}
 }
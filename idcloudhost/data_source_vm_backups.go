@@ -0,0 +1,106 @@
+package idcloudhost
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// vmBackup is a restore point taken by the automatic backup of a VM, see the
+// backup attribute of idcloudhost_vm.
+type vmBackup struct {
+	UUID      string `json:"uuid"`
+	VMUUID    string `json:"vm_uuid"`
+	SizeGB    int    `json:"size_gb"`
+	Status    string `json:"status"`
+	CreatedAt string `json:"created_at"`
+}
+
+// listVMBackups returns the restore points of a VM, most recent first.
+func listVMBackups(ctx context.Context, cfg *Config, region string, vmUUID string) ([]vmBackup, error) {
+	var backups []vmBackup
+	path := fmt.Sprintf("%s/user-resource/vm/backups", region)
+	if err := cfg.apiRequest(ctx, http.MethodGet, path, url.Values{"uuid": {vmUUID}}, &backups); err != nil {
+		return nil, err
+	}
+	sort.SliceStable(backups, func(i, j int) bool {
+		return backups[i].CreatedAt > backups[j].CreatedAt
+	})
+	return backups, nil
+}
+
+func dataSourceVMBackupsRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	cfg := m.(*Config)
+	vmUUID := d.Get("vm_uuid").(string)
+
+	backups, err := listVMBackups(ctx, cfg, cfg.resourceRegion(d), vmUUID)
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Unable to list VM backups",
+			Detail:   fmt.Sprint(err),
+		})
+		return diags
+	}
+
+	backupList := []map[string]interface{}{}
+	for _, backup := range backups {
+		backupList = append(backupList, map[string]interface{}{
+			"created_at": backup.CreatedAt,
+			"size":       backup.SizeGB,
+			"status":     backup.Status,
+			"uuid":       backup.UUID,
+		})
+	}
+	if err := d.Set("backups", backupList); err != nil {
+		return diag.FromErr(err)
+	}
+	d.SetId(vmUUID)
+	return diags
+}
+
+func dataSourceVMBackups() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceVMBackupsRead,
+		Schema: map[string]*schema.Schema{
+			"backups": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"created_at": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"size": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"status": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"uuid": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
+			"region": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"vm_uuid": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+		},
+	}
+}
@@ -0,0 +1,43 @@
+package idcloudhost
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccIdcloudhostVMBackupsDataSource_basic(t *testing.T) {
+	api := newFakeAPI(t)
+	vm := api.addVM("web")
+	older := api.addBackup(vm.UUID, "2022-11-01 01:00:00")
+	newer := api.addBackup(vm.UUID, "2022-11-02 01:00:00")
+	api.addBackup(api.addVM("db").UUID, "2022-11-03 01:00:00")
+	dataSourceName := "data.idcloudhost_vm_backups.test"
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: testAccProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccVMBackupsDataSourceConfig(api, vm.UUID),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(dataSourceName, "id", vm.UUID),
+					resource.TestCheckResourceAttr(dataSourceName, "backups.#", "2"),
+					resource.TestCheckResourceAttr(dataSourceName, "backups.0.uuid", newer.UUID),
+					resource.TestCheckResourceAttr(dataSourceName, "backups.0.created_at", newer.CreatedAt),
+					resource.TestCheckResourceAttr(dataSourceName, "backups.0.size", "20"),
+					resource.TestCheckResourceAttr(dataSourceName, "backups.0.status", "available"),
+					resource.TestCheckResourceAttr(dataSourceName, "backups.1.uuid", older.UUID),
+				),
+			},
+		},
+	})
+}
+
+func testAccVMBackupsDataSourceConfig(api *fakeAPI, vmUUID string) string {
+	return api.providerConfig() + fmt.Sprintf(`
+data "idcloudhost_vm_backups" "test" {
+  vm_uuid = %q
+}
+`, vmUUID)
+}
@@ -0,0 +1,56 @@
+package idcloudhost
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccIdcloudhostVMDataSource_basic(t *testing.T) {
+	api := newFakeAPI(t)
+	vm := api.addVM("web-1")
+	api.addVM("web-2")
+	api.addVM("web-2")
+	dataSourceName := "data.idcloudhost_vm.test"
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: testAccProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccVMDataSourceConfig(api, "name", "web-1"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(dataSourceName, "id", vm.UUID),
+					resource.TestCheckResourceAttr(dataSourceName, "uuid", vm.UUID),
+					resource.TestCheckResourceAttr(dataSourceName, "hostname", vm.Hostname),
+					resource.TestCheckResourceAttr(dataSourceName, "private_ipv4", vm.PrivateIPv4),
+					resource.TestCheckResourceAttr(dataSourceName, "disks", "20"),
+					resource.TestCheckResourceAttr(dataSourceName, "storage.#", "1"),
+				),
+			},
+			{
+				Config: testAccVMDataSourceConfig(api, "uuid", vm.UUID),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(dataSourceName, "name", "web-1"),
+				),
+			},
+			{
+				Config:      testAccVMDataSourceConfig(api, "hostname", "web-2"),
+				ExpectError: regexp.MustCompile("Multiple VMs found"),
+			},
+			{
+				Config:      testAccVMDataSourceConfig(api, "name", "web-3"),
+				ExpectError: regexp.MustCompile("No VM found"),
+			},
+		},
+	})
+}
+
+func testAccVMDataSourceConfig(api *fakeAPI, key string, value string) string {
+	return api.providerConfig() + fmt.Sprintf(`
+data "idcloudhost_vm" "test" {
+  %s = %q
+}
+`, key, value)
+}
@@ -0,0 +1,328 @@
+package idcloudhost
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	idcloudhostVM "github.com/bapung/idcloudhost-go-client-library/idcloudhost/vm"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func dataSourceVirtualMachinesRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	cfg := m.(*Config)
+	c, err := cfg.Client(cfg.resourceRegion(d))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	var diags diag.Diagnostics
+
+	var nameRegex *regexp.Regexp
+	if v, ok := d.GetOk("name_regex"); ok {
+		nameRegex = regexp.MustCompile(v.(string))
+	}
+
+	vmApi := c.VM
+	if err := vmApi.ListAll(); err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Unable to list VMs",
+			Detail:   fmt.Sprint(err),
+		})
+		return diags
+	}
+	filteredVMs := filterVMs(vmApi.VMList, d.Get("filter").(*schema.Set).List(), nameRegex)
+	vmList, err := adaptVMListStructToMap(&filteredVMs)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("vms", vmList); err != nil {
+		return diag.FromErr(err)
+	}
+	d.SetId(vmListId(filteredVMs))
+	return diags
+}
+
+func dataSourceVirtualMachines() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceVirtualMachinesRead,
+		Schema: map[string]*schema.Schema{
+			"filter": {
+				Type:     schema.TypeSet,
+				Optional: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"name": {
+							Type:     schema.TypeString,
+							Required: true,
+							ValidateFunc: func(val interface{}, key string) (warns []string, errs []error) {
+								v := val.(string)
+								if _, ok := vmFilterFields[v]; !ok {
+									errs = append(errs, fmt.Errorf("%q must be one of %s, got: %s", key, strings.Join(vmFilterFieldNames(), ", "), v))
+								}
+								return
+							},
+						},
+						"values": {
+							Type:     schema.TypeList,
+							Required: true,
+							MinItems: 1,
+							Elem: &schema.Schema{
+								Type: schema.TypeString,
+							},
+						},
+					},
+				},
+			},
+			"name_regex": {
+				Type:     schema.TypeString,
+				Optional: true,
+				ValidateFunc: func(val interface{}, key string) (warns []string, errs []error) {
+					v := val.(string)
+					if _, err := regexp.Compile(v); err != nil {
+						errs = append(errs, fmt.Errorf("%q must be a valid regular expression, got: %s", key, err))
+					}
+					return
+				},
+			},
+			"region": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"vms": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"backup": {
+							Type:     schema.TypeBool,
+							Computed: true,
+						},
+						"billing_account_id": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"created_at": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"description": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"hostname": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"hypervisor_id": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"id": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"mac": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"memory": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"name": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"os_name": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"os_version": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"private_ipv4": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"status": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"storage": {
+							Type:     schema.TypeList,
+							Computed: true,
+							Elem: &schema.Resource{
+								Schema: map[string]*schema.Schema{
+									"created_at": {
+										Type:     schema.TypeString,
+										Computed: true,
+									},
+									"id": {
+										Type:     schema.TypeInt,
+										Computed: true,
+									},
+									"name": {
+										Type:     schema.TypeString,
+										Computed: true,
+									},
+									"pool": {
+										Type:     schema.TypeString,
+										Computed: true,
+									},
+									"primary": {
+										Type:     schema.TypeBool,
+										Computed: true,
+									},
+									"replica": {
+										Type:     schema.TypeList,
+										Computed: true,
+										Elem: &schema.Schema{
+											Type: schema.TypeString,
+										},
+									},
+									"shared": {
+										Type:     schema.TypeBool,
+										Computed: true,
+									},
+									"size": {
+										Type:     schema.TypeInt,
+										Computed: true,
+									},
+									"type": {
+										Type:     schema.TypeString,
+										Computed: true,
+									},
+									"updated_at": {
+										Type:     schema.TypeString,
+										Computed: true,
+									},
+									"user_id": {
+										Type:     schema.TypeInt,
+										Computed: true,
+									},
+									"uuid": {
+										Type:     schema.TypeString,
+										Computed: true,
+									},
+								},
+							},
+						},
+						"tags": {
+							Type:     schema.TypeList,
+							Computed: true,
+							Elem: &schema.Schema{
+								Type: schema.TypeString,
+							},
+						},
+						"updated_at": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"user_id": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"username": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"uuid": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"vcpu": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// vmFilterFields maps the field names accepted in filter blocks to the VM
+// values they are matched against.
+var vmFilterFields = map[string]func(vm *idcloudhostVM.VM) []string{
+	"billing_account_id": func(vm *idcloudhostVM.VM) []string { return []string{strconv.Itoa(vm.BillingAccount)} },
+	"hostname":           func(vm *idcloudhostVM.VM) []string { return []string{vm.Hostname} },
+	"hypervisor_id":      func(vm *idcloudhostVM.VM) []string { return []string{vm.HypervisorId} },
+	"name":               func(vm *idcloudhostVM.VM) []string { return []string{vm.Name} },
+	"os_name":            func(vm *idcloudhostVM.VM) []string { return []string{vm.OSName} },
+	"os_version":         func(vm *idcloudhostVM.VM) []string { return []string{vm.OSVersion} },
+	"status":             func(vm *idcloudhostVM.VM) []string { return []string{vm.Status} },
+	"tags":               func(vm *idcloudhostVM.VM) []string { return vm.Tags },
+	"uuid":               func(vm *idcloudhostVM.VM) []string { return []string{vm.UUID} },
+}
+
+func vmFilterFieldNames() []string {
+	var names []string
+	for name := range vmFilterFields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// filterVMs returns the VMs matching every filter and nameRegex, sorted by
+// name and UUID so the result does not depend on the API ordering. A filter
+// matches when the VM field equals any of its values, for tags when any of
+// the VM tags does.
+func filterVMs(vms []idcloudhostVM.VM, filters []interface{}, nameRegex *regexp.Regexp) []idcloudhostVM.VM {
+	result := []idcloudhostVM.VM{}
+	for i := range vms {
+		vm := &vms[i]
+		if nameRegex != nil && !nameRegex.MatchString(vm.Name) {
+			continue
+		}
+		matches := true
+		for _, f := range filters {
+			filter := f.(map[string]interface{})
+			fieldValues := vmFilterFields[filter["name"].(string)](vm)
+			if !containsAny(fieldValues, filter["values"].([]interface{})) {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			result = append(result, *vm)
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		if result[i].Name != result[j].Name {
+			return result[i].Name < result[j].Name
+		}
+		return result[i].UUID < result[j].UUID
+	})
+	return result
+}
+
+func containsAny(fieldValues []string, values []interface{}) bool {
+	for _, fieldValue := range fieldValues {
+		for _, v := range values {
+			if fieldValue == v.(string) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// vmListId derives the data source ID from the UUIDs of the listed VMs, so it
+// only changes when the result does.
+func vmListId(vms []idcloudhostVM.VM) string {
+	var uuids []string
+	for _, vm := range vms {
+		uuids = append(uuids, vm.UUID)
+	}
+	hash := sha1.Sum([]byte(strings.Join(uuids, ",")))
+	return hex.EncodeToString(hash[:])
+}
@@ -0,0 +1,108 @@
+package idcloudhost
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+
+	idcloudhostVM "github.com/bapung/idcloudhost-go-client-library/idcloudhost/vm"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccIdcloudhostVMsDataSource_basic(t *testing.T) {
+	api := newFakeAPI(t)
+	vm := api.addVM("existing-vm")
+	api.addVM("other-vm")
+	dataSourceName := "data.idcloudhost_vms.test"
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: testAccProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: api.providerConfig() + `
+data "idcloudhost_vms" "test" {}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrSet(dataSourceName, "id"),
+					resource.TestCheckResourceAttr(dataSourceName, "vms.#", "2"),
+					resource.TestCheckResourceAttr(dataSourceName, "vms.0.uuid", vm.UUID),
+					resource.TestCheckResourceAttr(dataSourceName, "vms.0.name", "existing-vm"),
+					resource.TestCheckResourceAttr(dataSourceName, "vms.0.private_ipv4", vm.PrivateIPv4),
+					resource.TestCheckResourceAttr(dataSourceName, "vms.0.storage.#", "1"),
+					resource.TestCheckResourceAttr(dataSourceName, "vms.0.storage.0.uuid", vm.Storage[0].UUID),
+					resource.TestCheckResourceAttr(dataSourceName, "vms.0.storage.0.size", "20"),
+					resource.TestCheckResourceAttr(dataSourceName, "vms.1.name", "other-vm"),
+				),
+			},
+			{
+				Config: api.providerConfig() + `
+data "idcloudhost_vms" "test" {
+  filter {
+    name   = "name"
+    values = ["other-vm"]
+  }
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(dataSourceName, "vms.#", "1"),
+					resource.TestCheckResourceAttr(dataSourceName, "vms.0.name", "other-vm"),
+				),
+			},
+		},
+	})
+}
+
+func TestFilterVMs(t *testing.T) {
+	vms := []idcloudhostVM.VM{
+		{UUID: "c", Name: "web-2", Status: "running", OSName: "ubuntu", BillingAccount: 1, Tags: []string{"web", "prod"}},
+		{UUID: "b", Name: "db-1", Status: "stopped", OSName: "debian", BillingAccount: 2, Tags: []string{"db"}},
+		{UUID: "a", Name: "web-1", Status: "running", OSName: "centos", BillingAccount: 1},
+		{UUID: "d", Name: "web-1", Status: "running", OSName: "ubuntu", BillingAccount: 2, Tags: []string{"web"}},
+	}
+	filter := func(name string, values ...interface{}) interface{} {
+		return map[string]interface{}{"name": name, "values": values}
+	}
+
+	cases := map[string]struct {
+		filters   []interface{}
+		nameRegex *regexp.Regexp
+		want      []string
+	}{
+		"no filter returns all sorted by name and uuid": {
+			want: []string{"b", "a", "d", "c"},
+		},
+		"values of one filter are or-ed": {
+			filters: []interface{}{filter("os_name", "ubuntu", "debian")},
+			want:    []string{"b", "d", "c"},
+		},
+		"filters are and-ed": {
+			filters: []interface{}{filter("status", "running"), filter("billing_account_id", "1")},
+			want:    []string{"a", "c"},
+		},
+		"tags match any tag": {
+			filters: []interface{}{filter("tags", "web")},
+			want:    []string{"d", "c"},
+		},
+		"name regex": {
+			nameRegex: regexp.MustCompile("^web-1$"),
+			filters:   []interface{}{filter("tags", "web")},
+			want:      []string{"d"},
+		},
+		"no match": {
+			filters: []interface{}{filter("status", "paused")},
+			want:    []string{},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := []string{}
+			for _, vm := range filterVMs(vms, tc.filters, tc.nameRegex) {
+				got = append(got, vm.UUID)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("got %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,1159 @@
+package idcloudhost
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	idcloudhostDisk "github.com/bapung/idcloudhost-go-client-library/idcloudhost/disk"
+	idcloudhostFloatingIP "github.com/bapung/idcloudhost-go-client-library/idcloudhost/floatingip"
+	idcloudhostVM "github.com/bapung/idcloudhost-go-client-library/idcloudhost/vm"
+)
+
+const (
+	fakeAPIToken            = "fake-api-token"
+	fakeAPIBillingAccountID = 1200132376
+	fakeAPIUserID           = 4817
+
+	// fakeVMStatusRestoring is reported while a VM is restored from a backup.
+	fakeVMStatusRestoring = "restoring"
+	// fakeVMStatusProvisioning is reported while a new VM is set up.
+	fakeVMStatusProvisioning = "provisioning"
+)
+
+// fakeAPI is an in-process stand-in for the IDCloudHost API. Responses are
+// encoded from the client library's own types so that they decode the same
+// way real API responses do.
+type fakeAPI struct {
+	t      *testing.T
+	server *httptest.Server
+
+	mu              sync.Mutex
+	nextID          int
+	vms             map[string]*idcloudhostVM.VM
+	floatingIPs     map[string]*floatingIP
+	billingAccounts []billingAccount
+	osImages        []osImage
+	locations       []location
+	networks        map[string]*network
+	firewalls       map[string]*firewall
+	snapshots       map[string]*vmSnapshot
+	backups         map[string]*vmBackup
+	// restores maps VM UUIDs to the backup they were last restored from.
+	restores          map[string]string
+	buckets           map[string]*objectStorageBucket
+	objectStorageKeys []objectStorageKey
+	// vmErrors maps HTTP methods to the error returned for requests on a VM.
+	vmErrors map[string]fakeAPIError
+	// networkListError is returned when listing networks, unless its status
+	// is 0.
+	networkListError fakeAPIError
+	// provisioningPolls is how many times VMs created through the API are
+	// reported as provisioning without a private IPv4, before they end up in
+	// provisionedStatus, running by default.
+	provisioningPolls  int
+	provisionedStatus  string
+	provisioning       map[string]int
+	provisionedAddress map[string]string
+	// userData maps VM UUIDs to the cloud-init user data they were created with.
+	userData map[string]string
+	// regions maps VM UUIDs and floating IP addresses to their location.
+	regions map[string]string
+}
+
+func newFakeAPI(t *testing.T) *fakeAPI {
+	t.Helper()
+	api := &fakeAPI{
+		t:           t,
+		nextID:      1000,
+		vms:         map[string]*idcloudhostVM.VM{},
+		floatingIPs: map[string]*floatingIP{},
+		billingAccounts: []billingAccount{
+			{ID: 1200132375, Name: "legacy"},
+			{ID: fakeAPIBillingAccountID, Name: "default", IsDefault: true},
+		},
+		locations: []location{
+			{Slug: "jkt01", DisplayName: "Jakarta 01", CountryCode: "ID", IsDefault: true},
+			{Slug: "sgp01", DisplayName: "Singapore 01", CountryCode: "SG", IsPreferred: true},
+		},
+		regions:   map[string]string{},
+		networks:  map[string]*network{},
+		firewalls: map[string]*firewall{},
+		snapshots: map[string]*vmSnapshot{},
+		backups:   map[string]*vmBackup{},
+		restores:  map[string]string{},
+		buckets:   map[string]*objectStorageBucket{},
+
+		provisionedStatus:  vmStatusRunning,
+		provisioning:       map[string]int{},
+		provisionedAddress: map[string]string{},
+		userData:           map[string]string{},
+		vmErrors:           map[string]fakeAPIError{},
+		osImages: []osImage{
+			{OSName: "ubuntu", OSVersion: "18.04", DisplayName: "Ubuntu 18.04 LTS"},
+			{OSName: "ubuntu", OSVersion: "20.04", DisplayName: "Ubuntu 20.04 LTS"},
+			{OSName: "debian", OSVersion: "9.1", DisplayName: "Debian 9.1"},
+			{OSName: "centos", OSVersion: "6.9.1611", DisplayName: "CentOS 6.9"},
+			{OSName: "centos", OSVersion: "7.3.1611", DisplayName: "CentOS 7.3"},
+		},
+	}
+	for _, l := range api.locations {
+		api.newNetwork("Default", "10.0.0.0/24", l.Slug).IsDefault = true
+	}
+	api.server = httptest.NewServer(http.HandlerFunc(api.serveHTTP))
+	t.Cleanup(api.server.Close)
+	return api
+}
+
+// config returns a provider configuration pointed at the fake API.
+func (api *fakeAPI) config() *Config {
+	api.t.Helper()
+	cfg := &Config{
+		AuthToken: fakeAPIToken,
+		Region:    "jkt01",
+		APIURL:    api.server.URL,
+	}
+	if err := cfg.loadAndValidate(); err != nil {
+		api.t.Fatalf("unable to configure client for fake API: %s", err)
+	}
+	return cfg
+}
+
+// providerConfig returns a provider block pointed at the fake API.
+func (api *fakeAPI) providerConfig() string {
+	return fmt.Sprintf(`
+provider "idcloudhost" {
+  auth_token = %q
+  api_url    = %q
+}
+`, fakeAPIToken, api.server.URL)
+}
+
+func (api *fakeAPI) newID() int {
+	api.nextID++
+	return api.nextID
+}
+
+func (api *fakeAPI) newUUID() string {
+	return fmt.Sprintf("00000000-0000-4000-8000-%012d", api.newID())
+}
+
+// addVM stores a running VM with a primary boot disk and returns it.
+func (api *fakeAPI) addVM(name string) idcloudhostVM.VM {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	vm := api.newVM(name, 20)
+	api.attachVM(vm.UUID, api.defaultNetwork("jkt01").UUID)
+	return *vm
+}
+
+func (api *fakeAPI) newVM(name string, diskSize int) *idcloudhostVM.VM {
+	vm := &idcloudhostVM.VM{
+		Id:             api.newID(),
+		UUID:           api.newUUID(),
+		Name:           name,
+		Hostname:       name,
+		Status:         vmStatusRunning,
+		BillingAccount: fakeAPIBillingAccountID,
+		UserId:         fakeAPIUserID,
+		VCPU:           1,
+		Memory:         1024,
+		OSName:         "ubuntu",
+		OSVersion:      "20.04",
+		Username:       "example",
+		PrivateIPv4:    fmt.Sprintf("10.0.0.%d", len(api.vms)+2),
+		MACAddress:     "52:54:00:00:00:01",
+		HypervisorId:   "hv-1",
+		CreatedAt:      "2022-11-01 10:00:00",
+		UpdatedAt:      "2022-11-01 10:00:00",
+		Storage: []idcloudhostDisk.DiskStorage{
+			api.newDisk(name+"-boot", diskSize, true),
+		},
+	}
+	api.vms[vm.UUID] = vm
+	api.regions[vm.UUID] = "jkt01"
+	return vm
+}
+
+func (api *fakeAPI) newDisk(name string, size int, primary bool) idcloudhostDisk.DiskStorage {
+	return idcloudhostDisk.DiskStorage{
+		Id:        api.newID(),
+		UUID:      api.newUUID(),
+		Name:      name,
+		Pool:      "default",
+		Primary:   primary,
+		Replica:   []string{},
+		SizeGB:    size,
+		Type:      "block",
+		CreatedAt: "2022-11-01 10:00:00",
+		UpdatedAt: "2022-11-01 10:00:00",
+	}
+}
+
+// vm returns a copy of the stored VM, or nil when it does not exist.
+func (api *fakeAPI) vm(uuid string) *idcloudhostVM.VM {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	vm, ok := api.vms[uuid]
+	if !ok {
+		return nil
+	}
+	vmCopy := *vm
+	return &vmCopy
+}
+
+// addFloatingIP stores a floating IP, assigned to vmUUID unless it is empty.
+func (api *fakeAPI) addFloatingIP(name string, billingAccountId int, vmUUID string) floatingIP {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	return *api.newFloatingIP(name, billingAccountId, vmUUID)
+}
+
+func (api *fakeAPI) newFloatingIP(name string, billingAccountId int, vmUUID string) *floatingIP {
+	id := api.newID()
+	fip := &floatingIP{
+		FloatingIP: idcloudhostFloatingIP.FloatingIP{
+			ID:         id,
+			Address:    fmt.Sprintf("103.0.%d.%d", id/256%256, id%256),
+			UserID:     fakeAPIUserID,
+			Type:       "public",
+			NetworkID:  "public-network",
+			Name:       name,
+			Enabled:    true,
+			AssignedTo: vmUUID,
+			CreatedAt:  "2022-11-01 10:00:00",
+			UpdatedAt:  "2022-11-01 10:00:00",
+		},
+		BillingAccountID: billingAccountId,
+	}
+	api.floatingIPs[fip.Address] = fip
+	api.regions[fip.Address] = "jkt01"
+	return fip
+}
+
+// deleteVM removes a VM as if it was deleted outside of Terraform.
+func (api *fakeAPI) deleteVM(uuid string) {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	delete(api.vms, uuid)
+	api.attachVM(uuid, "")
+	for _, fw := range api.firewalls {
+		fw.VMUUIDs = removeString(fw.VMUUIDs, uuid)
+	}
+}
+
+// floatingIP returns a copy of the stored floating IP, or nil when it does not
+// exist.
+func (api *fakeAPI) floatingIP(address string) *floatingIP {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	fip, ok := api.floatingIPs[address]
+	if !ok {
+		return nil
+	}
+	fipCopy := *fip
+	return &fipCopy
+}
+
+// region returns the location a VM or floating IP was created in.
+func (api *fakeAPI) region(key string) string {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	return api.regions[key]
+}
+
+func (api *fakeAPI) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("apikey") != fakeAPIToken {
+		api.writeError(w, http.StatusUnauthorized, "invalid apikey")
+		return
+	}
+	if err := parseFakeAPIForm(r); err != nil {
+		api.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	api.mu.Lock()
+	defer api.mu.Unlock()
+
+	// global endpoints look like /v1/<resource path>
+	path := strings.Trim(strings.TrimPrefix(r.URL.Path, "/v1/"), "/")
+	switch path {
+	case "payment/billing_account/list":
+		api.writeJSON(w, api.billingAccounts)
+		return
+	case "config/locations":
+		api.writeJSON(w, api.locations)
+		return
+	case "storage/bucket":
+		api.handleBucket(w, r)
+		return
+	case "storage/user/keys":
+		api.handleObjectStorageKeys(w, r)
+		return
+	}
+
+	// regional endpoints look like /v1/<location>/<resource path>
+	parts := strings.SplitN(path, "/", 2)
+	if len(parts) < 2 {
+		api.writeError(w, http.StatusNotFound, "unknown endpoint")
+		return
+	}
+	region, resourcePath := parts[0], parts[1]
+	if !api.knownRegion(region) {
+		api.writeError(w, http.StatusNotFound, "unknown location")
+		return
+	}
+	// objects are only visible in the location they were created in
+	keys := append([]string{r.Form.Get("uuid"), r.Form.Get("vm_uuid"), r.Form.Get("network_uuid")}, strings.Split(resourcePath, "/")...)
+	for _, key := range keys {
+		if objectRegion, ok := api.regions[key]; ok && objectRegion != region {
+			api.writeError(w, http.StatusNotFound, "not found")
+			return
+		}
+	}
+
+	switch {
+	case resourcePath == "config/vm_images":
+		api.writeJSON(w, api.osImages)
+	case resourcePath == "user-resource/vm/list":
+		api.listVMs(w, r, region)
+	case resourcePath == "user-resource/vm":
+		api.handleVM(w, r, region)
+	case resourcePath == "user-resource/vm/start":
+		api.setVMStatus(w, r, vmStatusRunning)
+	case resourcePath == "user-resource/vm/stop":
+		api.setVMStatus(w, r, vmStatusStopped)
+	case resourcePath == "user-resource/vm/backup":
+		api.toggleVMBackup(w, r)
+	case resourcePath == "user-resource/vm/backups":
+		api.listBackups(w, r)
+	case resourcePath == "user-resource/vm/backup/restore":
+		api.restoreBackup(w, r)
+	case resourcePath == "user-resource/vm/snapshot":
+		api.handleSnapshot(w, r, region)
+	case resourcePath == "user-resource/vm/storage":
+		api.handleDisk(w, r)
+	case resourcePath == "network/networks":
+		api.listNetworks(w, region)
+	case resourcePath == "network/network" && r.Method == http.MethodPost:
+		n := api.newNetwork(r.Form.Get("name"), r.Form.Get("subnet"), region)
+		api.writeJSON(w, n)
+	case strings.HasPrefix(resourcePath, "network/network/"):
+		api.handleNetwork(w, r, strings.Split(strings.TrimPrefix(resourcePath, "network/network/"), "/"))
+	case resourcePath == "network/firewall" && r.Method == http.MethodPost:
+		api.createFirewall(w, r, region)
+	case strings.HasPrefix(resourcePath, "network/firewall/"):
+		api.handleFirewall(w, r, strings.Split(strings.TrimPrefix(resourcePath, "network/firewall/"), "/"))
+	case resourcePath == "network/ip_addresses":
+		api.handleFloatingIPs(w, r, region)
+	case strings.HasPrefix(resourcePath, "network/ip_addresses/"):
+		api.handleFloatingIP(w, r, strings.Split(strings.TrimPrefix(resourcePath, "network/ip_addresses/"), "/"))
+	default:
+		api.writeError(w, http.StatusNotFound, "unknown endpoint")
+	}
+}
+
+// parseFakeAPIForm parses query and form body for every method, the API
+// accepts form bodies on DELETE requests too.
+func parseFakeAPIForm(r *http.Request) error {
+	if r.Method != http.MethodDelete {
+		return r.ParseForm()
+	}
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return err
+	}
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		return err
+	}
+	for key, values := range r.URL.Query() {
+		form[key] = append(form[key], values...)
+	}
+	r.Form = form
+	return nil
+}
+
+func (api *fakeAPI) knownRegion(region string) bool {
+	for _, l := range api.locations {
+		if l.Slug == region {
+			return true
+		}
+	}
+	return false
+}
+
+func (api *fakeAPI) listVMs(w http.ResponseWriter, r *http.Request, region string) {
+	vmList := []idcloudhostVM.VM{}
+	for _, vm := range api.vms {
+		if api.regions[vm.UUID] != region {
+			continue
+		}
+		vmList = append(vmList, *vm)
+	}
+	sort.Slice(vmList, func(i, j int) bool { return vmList[i].Id < vmList[j].Id })
+	api.writeJSON(w, vmList)
+}
+
+func (api *fakeAPI) handleVM(w http.ResponseWriter, r *http.Request, region string) {
+	if r.Method == http.MethodPost {
+		api.createVM(w, r, region)
+		return
+	}
+
+	if vmErr, ok := api.vmErrors[r.Method]; ok {
+		api.writeError(w, vmErr.status, vmErr.message)
+		return
+	}
+	vm, ok := api.vms[r.Form.Get("uuid")]
+	if !ok {
+		api.writeError(w, http.StatusNotFound, "VM not found")
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		// restores complete on the next GET as if it took a while
+		if vm.Status == fakeVMStatusRestoring {
+			vm.Status = vmStatusRunning
+		}
+		if polls, ok := api.provisioning[vm.UUID]; ok {
+			if polls > 0 {
+				api.provisioning[vm.UUID]--
+			} else {
+				delete(api.provisioning, vm.UUID)
+				vm.Status = api.provisionedStatus
+				vm.PrivateIPv4 = api.provisionedAddress[vm.UUID]
+			}
+		}
+		api.writeJSON(w, vm)
+	case http.MethodPatch:
+		if name := r.Form.Get("name"); name != "" {
+			vm.Name = name
+		}
+		if vcpu := formInt(r, "vcpu"); vcpu > 0 {
+			if vcpu != vm.VCPU && vm.Status != vmStatusStopped {
+				api.writeError(w, http.StatusConflict, "VM must be stopped to change vcpu")
+				return
+			}
+			vm.VCPU = vcpu
+		}
+		if memory := formInt(r, "ram"); memory > 0 {
+			if memory != vm.Memory && vm.Status != vmStatusStopped {
+				api.writeError(w, http.StatusConflict, "VM must be stopped to change ram")
+				return
+			}
+			vm.Memory = memory
+		}
+		api.writeJSON(w, vm)
+	case http.MethodDelete:
+		delete(api.vms, vm.UUID)
+		api.attachVM(vm.UUID, "")
+		for _, fw := range api.firewalls {
+			fw.VMUUIDs = removeString(fw.VMUUIDs, vm.UUID)
+		}
+		api.writeJSON(w, map[string]bool{"success": true})
+	default:
+		api.writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+func (api *fakeAPI) createVM(w http.ResponseWriter, r *http.Request, region string) {
+	for _, key := range []string{"name", "os_name", "os_version", "username", "password", "billing_account_id"} {
+		if r.Form.Get(key) == "" {
+			api.writeError(w, http.StatusBadRequest, fmt.Sprintf("%s is required", key))
+			return
+		}
+	}
+	if snapshotUUID := r.Form.Get("source_replica"); snapshotUUID != "" {
+		snapshot, ok := api.snapshots[snapshotUUID]
+		if !ok || snapshot.Status != snapshotStatusAvailable {
+			api.writeError(w, http.StatusBadRequest, "source replica not available")
+			return
+		}
+		if formInt(r, "disks") < snapshot.SizeGB {
+			api.writeError(w, http.StatusBadRequest, "disks smaller than source replica")
+			return
+		}
+	}
+	if networkUUID := r.Form.Get("network_uuid"); networkUUID != "" {
+		if _, ok := api.networks[networkUUID]; !ok {
+			api.writeError(w, http.StatusBadRequest, "network not found")
+			return
+		}
+	}
+	vm := api.newVM(r.Form.Get("name"), formInt(r, "disks"))
+	// the API stores OS names and usernames in lower case
+	vm.OSName = strings.ToLower(r.Form.Get("os_name"))
+	vm.OSVersion = r.Form.Get("os_version")
+	vm.Username = strings.ToLower(r.Form.Get("username"))
+	vm.Description = r.Form.Get("description")
+	vm.BillingAccount = formInt(r, "billing_account_id")
+	vm.VCPU = formInt(r, "vcpu")
+	vm.Memory = formInt(r, "ram")
+	vm.Backup = r.Form.Get("backup") == "true"
+	api.userData[vm.UUID] = r.Form.Get("cloud_init")
+	api.regions[vm.UUID] = region
+	if api.provisioningPolls > 0 || api.provisionedStatus != vmStatusRunning {
+		api.provisioning[vm.UUID] = api.provisioningPolls
+		api.provisionedAddress[vm.UUID] = vm.PrivateIPv4
+		vm.Status = fakeVMStatusProvisioning
+		vm.PrivateIPv4 = ""
+	}
+	if networkUUID := r.Form.Get("network_uuid"); networkUUID != "" {
+		api.attachVM(vm.UUID, networkUUID)
+	} else {
+		api.attachVM(vm.UUID, api.defaultNetwork(region).UUID)
+	}
+	api.writeJSON(w, vm)
+}
+
+func (api *fakeAPI) setVMStatus(w http.ResponseWriter, r *http.Request, status string) {
+	vm, ok := api.vms[r.Form.Get("uuid")]
+	if !ok {
+		api.writeError(w, http.StatusNotFound, "VM not found")
+		return
+	}
+	vm.Status = status
+	api.writeJSON(w, vm)
+}
+
+// vmUserData returns the cloud-init user data the VM was created with.
+func (api *fakeAPI) vmUserData(uuid string) string {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	return api.userData[uuid]
+}
+
+// slowProvisioning makes VMs created from now on report provisioning for
+// polls GETs before they end up in status.
+func (api *fakeAPI) slowProvisioning(polls int, status string) {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	api.provisioningPolls = polls
+	api.provisionedStatus = status
+}
+
+type fakeAPIError struct {
+	status  int
+	message string
+}
+
+// failVMRequests makes requests on a VM with method fail with status and
+// message, until it is called again with status 0.
+func (api *fakeAPI) failVMRequests(method string, status int, message string) {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	if status == 0 {
+		delete(api.vmErrors, method)
+		return
+	}
+	api.vmErrors[method] = fakeAPIError{status: status, message: message}
+}
+
+// failNetworkListing makes listing networks fail with status and message,
+// until it is called again with status 0.
+func (api *fakeAPI) failNetworkListing(status int, message string) {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	api.networkListError = fakeAPIError{status: status, message: message}
+}
+
+// setBackup changes the backup of a VM as if it was done outside of
+// Terraform.
+func (api *fakeAPI) setBackup(uuid string, enabled bool) {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	api.vms[uuid].Backup = enabled
+}
+
+func (api *fakeAPI) toggleVMBackup(w http.ResponseWriter, r *http.Request) {
+	vm, ok := api.vms[r.Form.Get("uuid")]
+	if !ok {
+		api.writeError(w, http.StatusNotFound, "VM not found")
+		return
+	}
+	vm.Backup = !vm.Backup
+	api.writeJSON(w, map[string]bool{"success": true})
+}
+
+func (api *fakeAPI) handleDisk(w http.ResponseWriter, r *http.Request) {
+	vm, ok := api.vms[r.Form.Get("uuid")]
+	if !ok {
+		api.writeError(w, http.StatusNotFound, "VM not found")
+		return
+	}
+
+	if r.Method == http.MethodPost {
+		disk := api.newDisk(fmt.Sprintf("%s-disk-%d", vm.Name, len(vm.Storage)), formInt(r, "size_gb"), false)
+		vm.Storage = append(vm.Storage, disk)
+		api.writeJSON(w, disk)
+		return
+	}
+
+	diskUUID := r.Form.Get("disk_uuid")
+	for i := range vm.Storage {
+		if vm.Storage[i].UUID != diskUUID {
+			continue
+		}
+		switch r.Method {
+		case http.MethodPatch:
+			size := formInt(r, "size_gb")
+			if size < vm.Storage[i].SizeGB {
+				api.writeError(w, http.StatusBadRequest, "disk cannot be shrunk")
+				return
+			}
+			vm.Storage[i].SizeGB = size
+			api.writeJSON(w, vm.Storage[i])
+		case http.MethodDelete:
+			vm.Storage = append(vm.Storage[:i], vm.Storage[i+1:]...)
+			api.writeJSON(w, map[string]bool{"success": true})
+		default:
+			api.writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		}
+		return
+	}
+	api.writeError(w, http.StatusNotFound, "disk not found")
+}
+
+func (api *fakeAPI) handleFloatingIPs(w http.ResponseWriter, r *http.Request, region string) {
+	if r.Method == http.MethodGet {
+		fipList := []floatingIP{}
+		for _, fip := range api.floatingIPs {
+			if api.regions[fip.Address] != region {
+				continue
+			}
+			fipList = append(fipList, *fip)
+		}
+		sort.Slice(fipList, func(i, j int) bool { return fipList[i].ID < fipList[j].ID })
+		api.writeJSON(w, fipList)
+		return
+	}
+	if r.Method != http.MethodPost {
+		api.writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	fip := api.newFloatingIP(r.Form.Get("name"), formInt(r, "billing_account_id"), "")
+	api.regions[fip.Address] = region
+	api.writeJSON(w, fip)
+}
+
+func (api *fakeAPI) handleFloatingIP(w http.ResponseWriter, r *http.Request, parts []string) {
+	fip, ok := api.floatingIPs[parts[0]]
+	if !ok {
+		api.writeError(w, http.StatusNotFound, "floating IP not found")
+		return
+	}
+
+	if len(parts) == 2 && r.Method == http.MethodPost {
+		switch parts[1] {
+		case "assign":
+			vmUUID := r.Form.Get("vm_uuid")
+			if _, ok := api.vms[vmUUID]; !ok {
+				api.writeError(w, http.StatusNotFound, "VM not found")
+				return
+			}
+			fip.AssignedTo = vmUUID
+		case "unassign":
+			fip.AssignedTo = ""
+		default:
+			api.writeError(w, http.StatusNotFound, "unknown endpoint")
+			return
+		}
+		api.writeJSON(w, fip)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		api.writeJSON(w, fip)
+	case http.MethodPatch:
+		fip.Name = r.Form.Get("name")
+		fip.BillingAccountID = formInt(r, "billing_account_id")
+		api.writeJSON(w, fip)
+	case http.MethodDelete:
+		delete(api.floatingIPs, fip.Address)
+		api.writeJSON(w, map[string]bool{"success": true})
+	default:
+		api.writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+// newNetwork stores a network in region, with a generated subnet when subnet
+// is empty.
+func (api *fakeAPI) newNetwork(name string, subnet string, region string) *network {
+	id := api.newID()
+	if subnet == "" {
+		subnet = fmt.Sprintf("10.%d.%d.0/24", id/256%256, id%256)
+	}
+	n := &network{
+		UUID:      api.newUUID(),
+		Name:      name,
+		Subnet:    subnet,
+		VlanID:    id,
+		Type:      "private",
+		VMUUIDs:   []string{},
+		CreatedAt: "2022-11-01 10:00:00",
+		UpdatedAt: "2022-11-01 10:00:00",
+	}
+	api.networks[n.UUID] = n
+	api.regions[n.UUID] = region
+	return n
+}
+
+func (api *fakeAPI) defaultNetwork(region string) *network {
+	for _, n := range api.networks {
+		if n.IsDefault && api.regions[n.UUID] == region {
+			return n
+		}
+	}
+	return nil
+}
+
+// addNetwork stores a network in region and returns a copy of it.
+func (api *fakeAPI) addNetwork(name string, region string) network {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	return *api.newNetwork(name, "", region)
+}
+
+// moveVM attaches a VM to another network as if it was done outside of
+// Terraform.
+func (api *fakeAPI) moveVM(vmUUID string, networkUUID string) {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	api.attachVM(vmUUID, networkUUID)
+}
+
+// attachVM moves a VM to a network, or detaches it from all networks when
+// networkUUID is empty.
+func (api *fakeAPI) attachVM(vmUUID string, networkUUID string) {
+	for _, n := range api.networks {
+		for i, uuid := range n.VMUUIDs {
+			if uuid == vmUUID {
+				n.VMUUIDs = append(n.VMUUIDs[:i], n.VMUUIDs[i+1:]...)
+				break
+			}
+		}
+	}
+	if n, ok := api.networks[networkUUID]; ok {
+		n.VMUUIDs = append(n.VMUUIDs, vmUUID)
+	}
+}
+
+// network returns a copy of the stored network, or nil when it does not
+// exist.
+func (api *fakeAPI) network(uuid string) *network {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	n, ok := api.networks[uuid]
+	if !ok {
+		return nil
+	}
+	networkCopy := *n
+	networkCopy.VMUUIDs = append([]string{}, n.VMUUIDs...)
+	return &networkCopy
+}
+
+func (api *fakeAPI) listNetworks(w http.ResponseWriter, region string) {
+	if api.networkListError.status != 0 {
+		api.writeError(w, api.networkListError.status, api.networkListError.message)
+		return
+	}
+	networkList := []network{}
+	for _, n := range api.networks {
+		if api.regions[n.UUID] == region {
+			networkList = append(networkList, *n)
+		}
+	}
+	sort.Slice(networkList, func(i, j int) bool { return networkList[i].VlanID < networkList[j].VlanID })
+	api.writeJSON(w, networkList)
+}
+
+func (api *fakeAPI) handleNetwork(w http.ResponseWriter, r *http.Request, parts []string) {
+	n, ok := api.networks[parts[0]]
+	if !ok {
+		api.writeError(w, http.StatusNotFound, "network not found")
+		return
+	}
+
+	if len(parts) == 2 && parts[1] == "default" && r.Method == http.MethodPost {
+		for _, other := range api.networks {
+			if api.regions[other.UUID] == api.regions[n.UUID] {
+				other.IsDefault = false
+			}
+		}
+		n.IsDefault = true
+		api.writeJSON(w, n)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		api.writeJSON(w, n)
+	case http.MethodPatch:
+		n.Name = r.Form.Get("name")
+		api.writeJSON(w, n)
+	case http.MethodDelete:
+		if n.IsDefault || len(n.VMUUIDs) > 0 {
+			api.writeError(w, http.StatusConflict, "network is default or in use")
+			return
+		}
+		delete(api.networks, n.UUID)
+		api.writeJSON(w, map[string]bool{"success": true})
+	default:
+		api.writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+// firewall returns a copy of the stored firewall, or nil when it does not
+// exist.
+func (api *fakeAPI) firewall(uuid string) *firewall {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	fw, ok := api.firewalls[uuid]
+	if !ok {
+		return nil
+	}
+	fwCopy := *fw
+	fwCopy.Rules = append([]firewallRule{}, fw.Rules...)
+	fwCopy.VMUUIDs = append([]string{}, fw.VMUUIDs...)
+	return &fwCopy
+}
+
+func (api *fakeAPI) createFirewall(w http.ResponseWriter, r *http.Request, region string) {
+	fw := &firewall{
+		UUID:        api.newUUID(),
+		Name:        r.Form.Get("name"),
+		Description: r.Form.Get("description"),
+		Rules:       []firewallRule{},
+		VMUUIDs:     []string{},
+		CreatedAt:   "2022-11-01 10:00:00",
+		UpdatedAt:   "2022-11-01 10:00:00",
+	}
+	api.firewalls[fw.UUID] = fw
+	api.regions[fw.UUID] = region
+	api.writeJSON(w, fw)
+}
+
+func (api *fakeAPI) handleFirewall(w http.ResponseWriter, r *http.Request, parts []string) {
+	fw, ok := api.firewalls[parts[0]]
+	if !ok {
+		api.writeError(w, http.StatusNotFound, "firewall not found")
+		return
+	}
+
+	switch {
+	case len(parts) == 1 && r.Method == http.MethodGet:
+		api.writeJSON(w, fw)
+	case len(parts) == 1 && r.Method == http.MethodPatch:
+		fw.Name = r.Form.Get("name")
+		fw.Description = r.Form.Get("description")
+		api.writeJSON(w, fw)
+	case len(parts) == 1 && r.Method == http.MethodDelete:
+		delete(api.firewalls, fw.UUID)
+		api.writeJSON(w, map[string]bool{"success": true})
+	case len(parts) == 2 && parts[1] == "rules" && r.Method == http.MethodPost:
+		rule := firewallRule{
+			UUID:        api.newUUID(),
+			Direction:   r.Form.Get("direction"),
+			Protocol:    r.Form.Get("protocol"),
+			PortStart:   formInt(r, "port_start"),
+			PortEnd:     formInt(r, "port_end"),
+			CIDR:        r.Form.Get("cidr"),
+			Description: r.Form.Get("description"),
+		}
+		fw.Rules = append(fw.Rules, rule)
+		api.writeJSON(w, rule)
+	case len(parts) == 3 && parts[1] == "rules" && r.Method == http.MethodDelete:
+		for i := range fw.Rules {
+			if fw.Rules[i].UUID == parts[2] {
+				fw.Rules = append(fw.Rules[:i], fw.Rules[i+1:]...)
+				api.writeJSON(w, map[string]bool{"success": true})
+				return
+			}
+		}
+		api.writeError(w, http.StatusNotFound, "rule not found")
+	case len(parts) == 2 && parts[1] == "vms" && r.Method == http.MethodPost:
+		vmUUID := r.Form.Get("vm_uuid")
+		if _, ok := api.vms[vmUUID]; !ok {
+			api.writeError(w, http.StatusNotFound, "VM not found")
+			return
+		}
+		fw.VMUUIDs = append(removeString(fw.VMUUIDs, vmUUID), vmUUID)
+		api.writeJSON(w, fw)
+	case len(parts) == 3 && parts[1] == "vms" && r.Method == http.MethodDelete:
+		fw.VMUUIDs = removeString(fw.VMUUIDs, parts[2])
+		api.writeJSON(w, fw)
+	default:
+		api.writeError(w, http.StatusNotFound, "unknown endpoint")
+	}
+}
+
+// snapshot returns a copy of the stored snapshot, or nil when it does not
+// exist.
+func (api *fakeAPI) snapshot(uuid string) *vmSnapshot {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	snapshot, ok := api.snapshots[uuid]
+	if !ok {
+		return nil
+	}
+	snapshotCopy := *snapshot
+	return &snapshotCopy
+}
+
+// addSnapshot stores an available snapshot of the boot disk of a VM, of
+// sizeGB regardless of the size of the disk.
+func (api *fakeAPI) addSnapshot(name string, vmUUID string, sizeGB int) vmSnapshot {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	snapshot := &vmSnapshot{
+		UUID:      api.newUUID(),
+		Name:      name,
+		VMUUID:    vmUUID,
+		SizeGB:    sizeGB,
+		Status:    snapshotStatusAvailable,
+		CreatedAt: "2022-11-01 10:00:00",
+	}
+	api.snapshots[snapshot.UUID] = snapshot
+	api.regions[snapshot.UUID] = api.regions[vmUUID]
+	return *snapshot
+}
+
+// handleSnapshot creates snapshots in the creating status and deletes them in
+// the deleting status, they complete on the next GET as if it took a while.
+func (api *fakeAPI) handleSnapshot(w http.ResponseWriter, r *http.Request, region string) {
+	if r.Method == http.MethodPost {
+		vm, ok := api.vms[r.Form.Get("uuid")]
+		if !ok {
+			api.writeError(w, http.StatusNotFound, "VM not found")
+			return
+		}
+		snapshot := &vmSnapshot{
+			UUID:      api.newUUID(),
+			Name:      r.Form.Get("name"),
+			VMUUID:    vm.UUID,
+			SizeGB:    vm.Storage[0].SizeGB,
+			Status:    snapshotStatusCreating,
+			CreatedAt: "2022-11-01 10:00:00",
+		}
+		api.snapshots[snapshot.UUID] = snapshot
+		api.regions[snapshot.UUID] = region
+		api.writeJSON(w, snapshot)
+		return
+	}
+
+	snapshot, ok := api.snapshots[r.Form.Get("uuid")]
+	if !ok {
+		api.writeError(w, http.StatusNotFound, "snapshot not found")
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		switch snapshot.Status {
+		case snapshotStatusCreating:
+			snapshot.Status = snapshotStatusAvailable
+		case snapshotStatusDeleting:
+			delete(api.snapshots, snapshot.UUID)
+		}
+		api.writeJSON(w, snapshot)
+	case http.MethodDelete:
+		snapshot.Status = snapshotStatusDeleting
+		api.writeJSON(w, snapshot)
+	default:
+		api.writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+// addBackup stores a restore point of the VM taken at createdAt and returns
+// it.
+func (api *fakeAPI) addBackup(vmUUID string, createdAt string) vmBackup {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	backup := &vmBackup{
+		UUID:      api.newUUID(),
+		VMUUID:    vmUUID,
+		SizeGB:    api.vms[vmUUID].Storage[0].SizeGB,
+		Status:    "available",
+		CreatedAt: createdAt,
+	}
+	api.backups[backup.UUID] = backup
+	return *backup
+}
+
+// restoredBackup returns the UUID of the backup the VM was last restored
+// from.
+func (api *fakeAPI) restoredBackup(vmUUID string) string {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	return api.restores[vmUUID]
+}
+
+func (api *fakeAPI) listBackups(w http.ResponseWriter, r *http.Request) {
+	if _, ok := api.vms[r.Form.Get("uuid")]; !ok {
+		api.writeError(w, http.StatusNotFound, "VM not found")
+		return
+	}
+	backups := []vmBackup{}
+	for _, backup := range api.backups {
+		if backup.VMUUID == r.Form.Get("uuid") {
+			backups = append(backups, *backup)
+		}
+	}
+	// the API does not sort them
+	sort.Slice(backups, func(i, j int) bool { return backups[i].UUID < backups[j].UUID })
+	api.writeJSON(w, backups)
+}
+
+// restoreBackup puts the VM in the restoring status, it is running again on
+// the next GET.
+func (api *fakeAPI) restoreBackup(w http.ResponseWriter, r *http.Request) {
+	vm, ok := api.vms[r.Form.Get("uuid")]
+	if !ok {
+		api.writeError(w, http.StatusNotFound, "VM not found")
+		return
+	}
+	backup, ok := api.backups[r.Form.Get("backup_uuid")]
+	if !ok || backup.VMUUID != vm.UUID {
+		api.writeError(w, http.StatusBadRequest, "backup not found for VM")
+		return
+	}
+	vm.Status = fakeVMStatusRestoring
+	api.restores[vm.UUID] = backup.UUID
+	api.writeJSON(w, vm)
+}
+
+// bucket returns a copy of the bucket, or nil if it does not exist.
+func (api *fakeAPI) bucket(name string) *objectStorageBucket {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	bucket, ok := api.buckets[name]
+	if !ok {
+		return nil
+	}
+	bucketCopy := *bucket
+	return &bucketCopy
+}
+
+func (api *fakeAPI) handleBucket(w http.ResponseWriter, r *http.Request) {
+	name := r.Form.Get("name")
+	if r.Method == http.MethodPost {
+		if _, ok := api.buckets[name]; ok {
+			api.writeError(w, http.StatusConflict, "bucket already exists")
+			return
+		}
+		bucket := &objectStorageBucket{
+			Name:             name,
+			BillingAccountID: formInt(r, "billing_account_id"),
+			ACL:              r.Form.Get("acl"),
+			Versioning:       r.Form.Get("versioning") == "true",
+			CreatedAt:        "2022-11-01 10:00:00",
+			ModifiedAt:       "2022-11-01 10:00:00",
+		}
+		api.buckets[name] = bucket
+		api.writeJSON(w, bucket)
+		return
+	}
+
+	bucket, ok := api.buckets[name]
+	if !ok {
+		api.writeError(w, http.StatusNotFound, "bucket not found")
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		api.writeJSON(w, bucket)
+	case http.MethodPatch:
+		bucket.BillingAccountID = formInt(r, "billing_account_id")
+		bucket.ACL = r.Form.Get("acl")
+		bucket.Versioning = r.Form.Get("versioning") == "true"
+		bucket.ModifiedAt = "2022-11-02 10:00:00"
+		api.writeJSON(w, bucket)
+	case http.MethodDelete:
+		delete(api.buckets, name)
+		api.writeJSON(w, map[string]bool{"success": true})
+	default:
+		api.writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+// objectStorageKey returns the key with the given access key, or nil if it
+// does not exist.
+func (api *fakeAPI) objectStorageKey(accessKey string) *objectStorageKey {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	for _, key := range api.objectStorageKeys {
+		if key.AccessKey == accessKey {
+			return &key
+		}
+	}
+	return nil
+}
+
+// handleObjectStorageKeys only returns secret keys when they are created, like
+// the API does.
+func (api *fakeAPI) handleObjectStorageKeys(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		keys := []objectStorageKey{}
+		for _, key := range api.objectStorageKeys {
+			keys = append(keys, objectStorageKey{AccessKey: key.AccessKey})
+		}
+		api.writeJSON(w, keys)
+	case http.MethodPost:
+		id := api.newID()
+		key := objectStorageKey{
+			AccessKey: fmt.Sprintf("FAKEACCESSKEY%07d", id),
+			SecretKey: fmt.Sprintf("fake-secret-key-%d", id),
+		}
+		api.objectStorageKeys = append(api.objectStorageKeys, key)
+		api.writeJSON(w, key)
+	case http.MethodDelete:
+		for i, key := range api.objectStorageKeys {
+			if key.AccessKey == r.Form.Get("access_key") {
+				api.objectStorageKeys = append(api.objectStorageKeys[:i], api.objectStorageKeys[i+1:]...)
+				api.writeJSON(w, map[string]bool{"success": true})
+				return
+			}
+		}
+		api.writeError(w, http.StatusNotFound, "key not found")
+	default:
+		api.writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+func removeString(values []string, value string) []string {
+	result := []string{}
+	for _, v := range values {
+		if v != value {
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+func formInt(r *http.Request, key string) int {
+	v, _ := strconv.Atoi(r.Form.Get(key))
+	return v
+}
+
+func (api *fakeAPI) writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		api.t.Errorf("fake API unable to encode response: %s", err)
+	}
+}
+
+func (api *fakeAPI) writeError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"message": message})
+}
@@ -1,27 +1,31 @@
 package idcloudhost
 
 import (
-	"encoding/json"
+	"context"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net"
 	"strconv"
+	"strings"
+	"time"
 
 	idcloudhostVM "github.com/bapung/idcloudhost-go-client-library/idcloudhost/vm"
 	idcloudhostDisk "github.com/bapung/idcloudhost-go-client-library/idcloudhost/disk"
-	idcloudhostFloatingIP "github.com/bapung/idcloudhost-go-client-library/idcloudhost/floatingip"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
 
 func setVmResource(d *schema.ResourceData, vm *idcloudhostVM.VM) error {
-	var storageList []map[string]interface{}
-	storageJson, err := json.Marshal(vm.Storage)
-	if err != nil {
+	if err := d.Set("storage", flattenDiskStorageList(vm.Storage)); err != nil {
 		return err
 	}
-	err = json.Unmarshal(storageJson, &storageList)
-	if err != nil {
-		return err
-	}
-	if err := d.Set("storage", storageList); err != nil {
-		return err
+	for _, disk := range vm.Storage {
+		if disk.Primary {
+			if err := d.Set("disks", disk.SizeGB); err != nil {
+				return err
+			}
+		}
 	}
 	if err := d.Set("backup", vm.Backup); err != nil {
 		return err
@@ -65,6 +69,11 @@ func setVmResource(d *schema.ResourceData, vm *idcloudhostVM.VM) error {
 	if err := d.Set("status", vm.Status); err != nil {
 		return err
 	}
+	if vm.Status == vmStatusRunning || vm.Status == vmStatusStopped {
+		if err := d.Set("power_state", vm.Status); err != nil {
+			return err
+		}
+	}
 	if err := d.Set("tags", vm.Tags); err != nil {
 		return err
 	}
@@ -87,19 +96,57 @@ func setVmResource(d *schema.ResourceData, vm *idcloudhostVM.VM) error {
 }
 
 func adaptVMListStructToMap(vmList *[]idcloudhostVM.VM) ([]map[string]interface{}, error) {
-	var vmMapList []map[string]interface{}
-	vmJson, err := json.Marshal(vmMapList)
-	if err != nil {
-		return nil, err
-	}
-	err = json.Unmarshal(vmJson, &vmMapList)
-	if err != nil {
-		return nil, err
+	vmMapList := make([]map[string]interface{}, 0, len(*vmList))
+	for _, vm := range *vmList {
+		vmMapList = append(vmMapList, map[string]interface{}{
+			"backup":             vm.Backup,
+			"billing_account_id": vm.BillingAccount,
+			"created_at":         vm.CreatedAt,
+			"description":        vm.Description,
+			"hostname":           vm.Hostname,
+			"hypervisor_id":      vm.HypervisorId,
+			"id":                 vm.Id,
+			"mac":                vm.MACAddress,
+			"memory":             vm.Memory,
+			"name":               vm.Name,
+			"os_name":            vm.OSName,
+			"os_version":         vm.OSVersion,
+			"private_ipv4":       vm.PrivateIPv4,
+			"status":             vm.Status,
+			"storage":            flattenDiskStorageList(vm.Storage),
+			"tags":               vm.Tags,
+			"updated_at":         vm.UpdatedAt,
+			"user_id":            vm.UserId,
+			"username":           vm.Username,
+			"uuid":               vm.UUID,
+			"vcpu":               vm.VCPU,
+		})
 	}
 	return vmMapList, nil
 }
 
-func setFloatingIP(d *schema.ResourceData, fip *idcloudhostFloatingIP.FloatingIP) error {
+func flattenDiskStorageList(storage []idcloudhostDisk.DiskStorage) []map[string]interface{} {
+	storageList := make([]map[string]interface{}, 0, len(storage))
+	for _, disk := range storage {
+		storageList = append(storageList, map[string]interface{}{
+			"created_at": disk.CreatedAt,
+			"id":         disk.Id,
+			"name":       disk.Name,
+			"pool":       disk.Pool,
+			"primary":    disk.Primary,
+			"replica":    disk.Replica,
+			"shared":     disk.Shared,
+			"size":       disk.SizeGB,
+			"type":       disk.Type,
+			"updated_at": disk.UpdatedAt,
+			"user_id":    disk.UserId,
+			"uuid":       disk.UUID,
+		})
+	}
+	return storageList
+}
+
+func setFloatingIP(d *schema.ResourceData, fip *floatingIP) error {
 	if err := d.Set("id", strconv.Itoa(fip.ID)); err != nil {
 		return err
 	}
@@ -109,7 +156,7 @@ func setFloatingIP(d *schema.ResourceData, fip *idcloudhostFloatingIP.FloatingIP
 	if err := d.Set("user_id", fip.UserID); err != nil {
 		return err
 	}
-	if err := d.Set("billing_account_id", fip.UserID); err != nil {
+	if err := d.Set("billing_account_id", fip.BillingAccountID); err != nil {
 		return err
 	}
 	if err := d.Set("type", fip.Type); err != nil {
@@ -137,6 +184,22 @@ func setFloatingIP(d *schema.ResourceData, fip *idcloudhostFloatingIP.FloatingIP
 	return nil
 }
 
+func flattenFloatingIP(fip *floatingIP) map[string]interface{} {
+	return map[string]interface{}{
+		"id":                 strconv.Itoa(fip.ID),
+		"address":            fip.Address,
+		"user_id":            fip.UserID,
+		"billing_account_id": fip.BillingAccountID,
+		"type":               fip.Type,
+		"network_id":         fip.NetworkID,
+		"name":               fip.Name,
+		"enabled":            fip.Enabled,
+		"created_at":         fip.CreatedAt,
+		"updated_at":         fip.UpdatedAt,
+		"assigned_to":        fip.AssignedTo,
+	}
+}
+
 func setDiskResource(d *schema.ResourceData, disk *idcloudhostDisk.DiskStorage) error {
 	if err := d.Set("created_at", disk.CreatedAt); err != nil {
 		return err
@@ -177,3 +240,144 @@ func setDiskResource(d *schema.ResourceData, disk *idcloudhostDisk.DiskStorage)
 
 	return nil
 }
+
+func validateDuration(val interface{}, key string) (warns []string, errs []error) {
+	v := val.(string)
+	if _, err := time.ParseDuration(v); err != nil {
+		errs = append(errs, fmt.Errorf("%q must be a valid duration such as \"30s\" or \"2m\", got: %s", key, v))
+	}
+	return
+}
+
+func validateCIDR(val interface{}, key string) (warns []string, errs []error) {
+	v := val.(string)
+	if _, ipNet, err := net.ParseCIDR(v); err != nil || ipNet.String() != v {
+		errs = append(errs, fmt.Errorf("%q must be a network address in CIDR notation such as \"10.10.0.0/24\", got: %s", key, v))
+	}
+	return
+}
+
+// maxUserDataSize is the largest cloud-init user data accepted by the API, in
+// bytes of plain text.
+const maxUserDataSize = 16384
+
+// userDataStateFunc stores a hash of the user data instead of the user data
+// itself, so large scripts do not bloat the state.
+func userDataStateFunc(val interface{}) string {
+	v, ok := val.(string)
+	if !ok || v == "" {
+		return ""
+	}
+	return hashUserData(v)
+}
+
+// userDataBase64StateFunc stores a hash of the decoded user data, matching
+// the hash userDataStateFunc stores for the same content in plain text.
+func userDataBase64StateFunc(val interface{}) string {
+	v, ok := val.(string)
+	if !ok || v == "" {
+		return ""
+	}
+	decoded, err := base64.StdEncoding.DecodeString(v)
+	if err != nil {
+		// rejected by validateUserDataBase64
+		return hashUserData(v)
+	}
+	return hashUserData(string(decoded))
+}
+
+func hashUserData(v string) string {
+	hash := sha1.Sum([]byte(v))
+	return hex.EncodeToString(hash[:])
+}
+
+func validateUserData(val interface{}, key string) (warns []string, errs []error) {
+	if v := val.(string); len(v) > maxUserDataSize {
+		errs = append(errs, fmt.Errorf("%q must not be larger than %d bytes, got: %d bytes", key, maxUserDataSize, len(v)))
+	}
+	return
+}
+
+func validateUserDataBase64(val interface{}, key string) (warns []string, errs []error) {
+	decoded, err := base64.StdEncoding.DecodeString(val.(string))
+	if err != nil {
+		errs = append(errs, fmt.Errorf("%q must be base64 encoded, e.g. with filebase64(): %s", key, err))
+		return
+	}
+	if len(decoded) > maxUserDataSize {
+		errs = append(errs, fmt.Errorf("%q must not be larger than %d bytes once decoded, got: %d bytes", key, maxUserDataSize, len(decoded)))
+	}
+	return
+}
+
+// vmUserData returns the user data of a VM from either user_data or the
+// decoded user_data_base64.
+func vmUserData(d *schema.ResourceData) (string, error) {
+	if v, ok := d.GetOk("user_data_base64"); ok {
+		decoded, err := base64.StdEncoding.DecodeString(v.(string))
+		if err != nil {
+			return "", fmt.Errorf("user_data_base64 is not base64 encoded: %s", err)
+		}
+		return string(decoded), nil
+	}
+	return d.Get("user_data").(string), nil
+}
+
+// importStateWithRegion imports a resource by its ID, optionally prefixed with
+// the region it lives in, e.g. "sgp01/<id>", for resources outside of the
+// provider region. idParts is the number of "/" separated parts of the ID.
+func importStateWithRegion(idParts int) schema.StateContextFunc {
+	return func(ctx context.Context, d *schema.ResourceData, m interface{}) ([]*schema.ResourceData, error) {
+		parts := strings.SplitN(d.Id(), "/", idParts+1)
+		if len(parts) == idParts+1 {
+			if err := d.Set("region", parts[0]); err != nil {
+				return nil, err
+			}
+			d.SetId(strings.Join(parts[1:], "/"))
+		}
+		return []*schema.ResourceData{d}, nil
+	}
+}
+
+// suppressCreateOnlyDiff suppresses the diff of a required create-only
+// attribute the API never returns, such as the initial password, once the
+// resource exists. Its state is empty after an import, and setting it would
+// only replace the resource to apply a value that is used at creation. On an
+// optional attribute it would also hide a value added to a resource created
+// without one, so those force a new resource instead.
+func suppressCreateOnlyDiff(k, old, new string, d *schema.ResourceData) bool {
+	return old == "" && d.Id() != ""
+}
+
+// suppressCaseDiff suppresses diffs of attributes the API normalizes to lower
+// case.
+func suppressCaseDiff(k, old, new string, d *schema.ResourceData) bool {
+	return strings.EqualFold(old, new)
+}
+
+func setNetworkResource(d *schema.ResourceData, n *network) error {
+	for key, value := range flattenNetwork(n) {
+		if err := d.Set(key, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func flattenNetwork(n *network) map[string]interface{} {
+	vmUUIDs := n.VMUUIDs
+	if vmUUIDs == nil {
+		vmUUIDs = []string{}
+	}
+	return map[string]interface{}{
+		"cidr":       n.Subnet,
+		"created_at": n.CreatedAt,
+		"default":    n.IsDefault,
+		"name":       n.Name,
+		"type":       n.Type,
+		"updated_at": n.UpdatedAt,
+		"uuid":       n.UUID,
+		"vlan_id":    n.VlanID,
+		"vm_uuids":   vmUUIDs,
+	}
+}
@@ -2,8 +2,9 @@ package idcloudhost
 
 import (
 	"context"
+	"fmt"
+	"time"
 
-	idcloudhostAPI "github.com/bapung/idcloudhost-go-client-library/idcloudhost/api"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
@@ -22,37 +23,162 @@ func Provider() *schema.Provider {
 				Optional: true,
 				Default:  "jkt01",
 			},
+			"default_billing_account_id": {
+				Type:        schema.TypeInt,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("IDCLOUDHOST_DEFAULT_BILLING_ACCOUNT_ID", 0),
+			},
+			"api_url": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("IDCLOUDHOST_API_URL", defaultAPIURL),
+			},
+			"http_proxy": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("IDCLOUDHOST_HTTP_PROXY", ""),
+			},
+			"insecure_skip_verify": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("IDCLOUDHOST_INSECURE_SKIP_VERIFY", false),
+			},
+			"ca_cert_file": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("IDCLOUDHOST_CA_CERT_FILE", ""),
+			},
+			"request_timeout": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc("IDCLOUDHOST_REQUEST_TIMEOUT", "60s"),
+				ValidateFunc: validateDuration,
+			},
+			"max_retries": {
+				Type:        schema.TypeInt,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("IDCLOUDHOST_MAX_RETRIES", 4),
+				ValidateFunc: func(val interface{}, key string) (warns []string, errs []error) {
+					v := val.(int)
+					if v < 0 {
+						errs = append(errs, fmt.Errorf("%q must not be negative, got: %d", key, v))
+					}
+					return
+				},
+			},
+			"retry_wait_min": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "1s",
+				ValidateFunc: validateDuration,
+			},
+			"retry_wait_max": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "30s",
+				ValidateFunc: validateDuration,
+			},
+			"max_requests_per_second": {
+				Type:        schema.TypeFloat,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("IDCLOUDHOST_MAX_REQUESTS_PER_SECOND", 5.0),
+				ValidateFunc: func(val interface{}, key string) (warns []string, errs []error) {
+					v := val.(float64)
+					if v < 0 {
+						errs = append(errs, fmt.Errorf("%q must not be negative, got: %v", key, v))
+					}
+					return
+				},
+			},
+			"burst": {
+				Type:     schema.TypeInt,
+				Optional: true,
+				Default:  10,
+				ValidateFunc: func(val interface{}, key string) (warns []string, errs []error) {
+					v := val.(int)
+					if v < 1 {
+						errs = append(errs, fmt.Errorf("%q must be at least 1, got: %d", key, v))
+					}
+					return
+				},
+			},
 		},
 		ResourcesMap: map[string]*schema.Resource{
-			"idcloudhost_vm":          resourceVirtualMachine(),
-			"idcloudhost_vm_disks":    resourceDisk(),
-			"idcloudhost_floating_ip": resourceFloatingIP(),
+			"idcloudhost_firewall":              resourceFirewall(),
+			"idcloudhost_firewall_attachment":   resourceFirewallAttachment(),
+			"idcloudhost_firewall_rule":         resourceFirewallRule(),
+			"idcloudhost_network":               resourceNetwork(),
+			"idcloudhost_object_storage_bucket": resourceObjectStorageBucket(),
+			"idcloudhost_object_storage_key":    resourceObjectStorageKey(),
+			"idcloudhost_vm":                    resourceVirtualMachine(),
+			"idcloudhost_vm_backup_restore":     resourceVMBackupRestore(),
+			"idcloudhost_vm_disks":              resourceDisk(),
+			"idcloudhost_vm_snapshot":           resourceVMSnapshot(),
+			"idcloudhost_floating_ip":           resourceFloatingIP(),
 		},
 		DataSourcesMap: map[string]*schema.Resource{
-			"idcloudhost_vms": dataSourceVirtualMachine(),
+			"idcloudhost_billing_accounts": dataSourceBillingAccounts(),
+			"idcloudhost_floating_ip":      dataSourceFloatingIP(),
+			"idcloudhost_floating_ips":     dataSourceFloatingIPs(),
+			"idcloudhost_locations":        dataSourceLocations(),
+			"idcloudhost_networks":         dataSourceNetworks(),
+			"idcloudhost_os_image":         dataSourceOSImage(),
+			"idcloudhost_os_images":        dataSourceOSImages(),
+			"idcloudhost_vm":               dataSourceVirtualMachine(),
+			"idcloudhost_vm_backups":       dataSourceVMBackups(),
+			"idcloudhost_vm_disks":         dataSourceDisks(),
+			"idcloudhost_vms":              dataSourceVirtualMachines(),
 		},
 		ConfigureContextFunc: providerConfigure,
 	}
 }
 
 func providerConfigure(ctx context.Context, d *schema.ResourceData) (interface{}, diag.Diagnostics) {
-	authToken := d.Get("auth_token").(string)
-	region := d.Get("region").(string)
-
 	// Warning or errors can be collected in a slice type
 	var diags diag.Diagnostics
 
-	if authToken != "" {
-		c, err := idcloudhostAPI.NewClient(authToken, region)
-		if err != nil {
-			return nil, diag.FromErr(err)
-		}
-		return c, diags
+	requestTimeout, err := time.ParseDuration(d.Get("request_timeout").(string))
+	if err != nil {
+		return nil, diag.FromErr(err)
+	}
+
+	retryWaitMin, err := time.ParseDuration(d.Get("retry_wait_min").(string))
+	if err != nil {
+		return nil, diag.FromErr(err)
 	}
-	c, err := idcloudhostAPI.NewClient("", "")
+	retryWaitMax, err := time.ParseDuration(d.Get("retry_wait_max").(string))
 	if err != nil {
 		return nil, diag.FromErr(err)
 	}
+	if retryWaitMin > retryWaitMax {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Invalid retry configuration",
+			Detail:   "retry_wait_min must not be greater than retry_wait_max",
+		})
+		return nil, diags
+	}
+
+	cfg := &Config{
+		AuthToken:          d.Get("auth_token").(string),
+		Region:             d.Get("region").(string),
+		APIURL:             d.Get("api_url").(string),
+		HTTPProxy:          d.Get("http_proxy").(string),
+		InsecureSkipVerify: d.Get("insecure_skip_verify").(bool),
+		CACertFile:         d.Get("ca_cert_file").(string),
+		RequestTimeout:     requestTimeout,
+		MaxRetries:         d.Get("max_retries").(int),
+		RetryWaitMin:       retryWaitMin,
+		RetryWaitMax:       retryWaitMax,
+		MaxRequestsPerSec:  d.Get("max_requests_per_second").(float64),
+		Burst:              d.Get("burst").(int),
+
+		DefaultBillingAccountID: d.Get("default_billing_account_id").(int),
+	}
+
+	if err := cfg.loadAndValidate(); err != nil {
+		return nil, diag.FromErr(err)
+	}
 
-	return c, diags
+	return cfg, diags
 }
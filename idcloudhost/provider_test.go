@@ -0,0 +1,71 @@
+package idcloudhost
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+var testAccProviderFactories = map[string]func() (*schema.Provider, error){
+	"idcloudhost": func() (*schema.Provider, error) {
+		return Provider(), nil
+	},
+}
+
+func TestMain(m *testing.M) {
+	// the fake API applies changes immediately, do not wait for it
+	vmPollDelay = 0
+	vmPollMinTimeout = 10 * time.Millisecond
+	os.Exit(m.Run())
+}
+
+func TestProvider(t *testing.T) {
+	if err := Provider().InternalValidate(); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+}
+
+// testResourceApply plans raw against state and applies the plan by calling
+// the resource functions directly, for tests that run without the Terraform
+// CLI. A validation or planning error is returned as diagnostics with state unchanged.
+func testResourceApply(t *testing.T, r *schema.Resource, state *terraform.InstanceState, raw map[string]interface{}, meta interface{}) (*terraform.InstanceState, diag.Diagnostics) {
+	t.Helper()
+	return testResourceApplyConfig(t, r, state, terraform.NewResourceConfigRaw(raw), meta)
+}
+
+// testResourceApplyConfig is testResourceApply for a prepared configuration,
+// such as one from testResourceConfigShimmed.
+func testResourceApplyConfig(t *testing.T, r *schema.Resource, state *terraform.InstanceState, config *terraform.ResourceConfig, meta interface{}) (*terraform.InstanceState, diag.Diagnostics) {
+	t.Helper()
+	ctx := context.Background()
+	if diags := r.Validate(config); diags.HasError() {
+		return state, diags
+	}
+	diff, err := r.Diff(ctx, state, config, meta)
+	if err != nil {
+		return state, diag.FromErr(err)
+	}
+	if diff == nil {
+		return state, nil
+	}
+	return r.Apply(ctx, state, diff, meta)
+}
+
+// testResourceConfigShimmed converts val to a configuration the way Terraform
+// passes it to the provider. Unlike terraform.NewResourceConfigRaw, this drops
+// empty sets and lists of blocks, as Terraform does.
+func testResourceConfigShimmed(t *testing.T, r *schema.Resource, val cty.Value) *terraform.ResourceConfig {
+	t.Helper()
+	schemaBlock := r.CoreConfigSchema()
+	val, err := schemaBlock.CoerceValue(val)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return terraform.NewResourceConfigShimmed(val, schemaBlock)
+}
@@ -3,10 +3,11 @@ package idcloudhost
 import (
 	"context"
 	"fmt"
+	"log"
 	"strings"
 	"time"
 
-	idcloudhostAPI "github.com/bapung/idcloudhost-go-client-library/idcloudhost/api"
+	idcloudhostDisk "github.com/bapung/idcloudhost-go-client-library/idcloudhost/disk"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
@@ -21,7 +22,7 @@ func resourceDisk() *schema.Resource {
 			Create: schema.DefaultTimeout(5 * time.Minute),
 		},
 		Importer: &schema.ResourceImporter{
-			StateContext: schema.ImportStatePassthroughContext,
+			StateContext: importStateWithRegion(2),
 		},
 		Schema: map[string]*schema.Schema{
 			"created_at": {
@@ -44,6 +45,12 @@ func resourceDisk() *schema.Resource {
 				Type:     schema.TypeBool,
 				Computed: true,
 			},
+			"region": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+				ForceNew: true,
+			},
 			"replica": {
 				Type:     schema.TypeList,
 				Computed: true,
@@ -84,7 +91,11 @@ func resourceDisk() *schema.Resource {
 }
 
 func resourceDiskCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	c := m.(*idcloudhostAPI.APIClient)
+	cfg := m.(*Config)
+	c, err := cfg.Client(cfg.resourceRegion(d))
+	if err != nil {
+		return diag.FromErr(err)
+	}
 	var diags diag.Diagnostics
 	diskApi := c.Disk
 
@@ -92,7 +103,7 @@ func resourceDiskCreate(ctx context.Context, d *schema.ResourceData, m interface
 	diskSize := d.Get("size").(int)
 
 	diskApi.Bind(vmUUID)
-	err := diskApi.Create(diskSize)
+	err = diskApi.Create(diskSize)
 	if err != nil {
 		diags = append(diags, diag.Diagnostic{
 			Severity: diag.Error,
@@ -112,15 +123,23 @@ func resourceDiskCreate(ctx context.Context, d *schema.ResourceData, m interface
 
 func resourceDiskRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	var diags diag.Diagnostics
-	c := m.(*idcloudhostAPI.APIClient)
+	cfg := m.(*Config)
+	c, err := cfg.Client(cfg.resourceRegion(d))
+	if err != nil {
+		return diag.FromErr(err)
+	}
 	diskApi := c.Disk
-	vmApi := c.VM
 
 	diskResourceId := strings.Split(d.Id(), "/")
 	vmUUID := diskResourceId[0]
 	diskUUID := diskResourceId[1]
 	diskApi.Bind(vmUUID)
-	err := vmApi.Get(vmUUID)
+	vm, err := getVM(ctx, cfg, cfg.resourceRegion(d), vmUUID)
+	if isNotFoundError(err) {
+		log.Printf("[WARN] VM %s of disk %s not found, removing disk from state", vmUUID, diskUUID)
+		d.SetId("")
+		return diags
+	}
 	if err != nil {
 		diags = append(diags, diag.Diagnostic{
 			Severity: diag.Error,
@@ -129,7 +148,12 @@ func resourceDiskRead(ctx context.Context, d *schema.ResourceData, m interface{}
 		})
 		return diags
 	}
-	err = diskApi.Get(diskUUID, &vmApi.VM.Storage)
+	if !hasDisk(vm.Storage, diskUUID) {
+		log.Printf("[WARN] Disk %s not attached to VM %s anymore, removing from state", diskUUID, vmUUID)
+		d.SetId("")
+		return diags
+	}
+	err = diskApi.Get(diskUUID, &vm.Storage)
 	if err != nil {
 		diags = append(diags, diag.Diagnostic{
 			Severity: diag.Error,
@@ -147,13 +171,20 @@ func resourceDiskRead(ctx context.Context, d *schema.ResourceData, m interface{}
 		})
 		return diags
 	}
+	if err := d.Set("region", cfg.resourceRegion(d)); err != nil {
+		return diag.FromErr(err)
+	}
 	return diags
 }
 
 func resourceDiskUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	var diags diag.Diagnostics
 	var newSize, oldSize int
-	c := m.(*idcloudhostAPI.APIClient)
+	cfg := m.(*Config)
+	c, err := cfg.Client(cfg.resourceRegion(d))
+	if err != nil {
+		return diag.FromErr(err)
+	}
 	diskApi := c.Disk
 
 	diskResourceId := strings.Split(d.Id(), "/")
@@ -184,7 +215,7 @@ func resourceDiskUpdate(ctx context.Context, d *schema.ResourceData, m interface
 		}
 	}
 	diskApi.Bind(vmUUID)
-	err := diskApi.Modify(diskUUID, newSize)
+	err = diskApi.Modify(diskUUID, newSize)
 	if err != nil {
 		diags = append(diags, diag.Diagnostic{
 			Severity: diag.Error,
@@ -207,7 +238,11 @@ func resourceDiskUpdate(ctx context.Context, d *schema.ResourceData, m interface
 
 func resourceDiskDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	var diags diag.Diagnostics
-	c := m.(*idcloudhostAPI.APIClient)
+	cfg := m.(*Config)
+	c, err := cfg.Client(cfg.resourceRegion(d))
+	if err != nil {
+		return diag.FromErr(err)
+	}
 
 	diskApi := c.Disk
 	diskResourceId := strings.Split(d.Id(), "/")
@@ -215,9 +250,18 @@ func resourceDiskDelete(ctx context.Context, d *schema.ResourceData, m interface
 	diskUUID := diskResourceId[1]
 
 	diskApi.Bind(vmUUID)
-	err := diskApi.Delete(diskUUID)
+	err = diskApi.Delete(diskUUID)
 	if err != nil {
 		return diag.FromErr(err)
 	}
 	return diags
-}
\ No newline at end of file
+}
+
+func hasDisk(storage []idcloudhostDisk.DiskStorage, diskUUID string) bool {
+	for _, disk := range storage {
+		if disk.UUID == diskUUID {
+			return true
+		}
+	}
+	return false
+}
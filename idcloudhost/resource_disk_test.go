@@ -0,0 +1,78 @@
+package idcloudhost
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestAccIdcloudhostDisk_basic(t *testing.T) {
+	api := newFakeAPI(t)
+	resourceName := "idcloudhost_vm_disks.test"
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: testAccProviderFactories,
+		CheckDestroy:      testAccCheckDiskDestroy(api),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccDiskConfig(api, 22),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckDiskExists(api, resourceName),
+					resource.TestCheckResourceAttr(resourceName, "size", "22"),
+					resource.TestCheckResourceAttr(resourceName, "primary", "false"),
+					resource.TestCheckResourceAttrPair(resourceName, "vm_uuid", "idcloudhost_vm.test", "uuid"),
+					resource.TestCheckResourceAttrSet(resourceName, "uuid"),
+				),
+			},
+			{
+				Config: testAccDiskConfig(api, 30),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckDiskExists(api, resourceName),
+					resource.TestCheckResourceAttr(resourceName, "size", "30"),
+				),
+			},
+		},
+	})
+}
+
+func testAccCheckDiskExists(api *fakeAPI, resourceName string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[resourceName]
+		if !ok {
+			return fmt.Errorf("not found: %s", resourceName)
+		}
+		diskResourceId := strings.Split(rs.Primary.ID, "/")
+		vm := api.vm(diskResourceId[0])
+		if vm == nil || !hasDisk(vm.Storage, diskResourceId[1]) {
+			return fmt.Errorf("disk %s does not exist in the API", rs.Primary.ID)
+		}
+		return nil
+	}
+}
+
+func testAccCheckDiskDestroy(api *fakeAPI) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		for _, rs := range s.RootModule().Resources {
+			if rs.Type != "idcloudhost_vm_disks" {
+				continue
+			}
+			diskResourceId := strings.Split(rs.Primary.ID, "/")
+			if vm := api.vm(diskResourceId[0]); vm != nil && hasDisk(vm.Storage, diskResourceId[1]) {
+				return fmt.Errorf("disk %s still exists", rs.Primary.ID)
+			}
+		}
+		return nil
+	}
+}
+
+func testAccDiskConfig(api *fakeAPI, size int) string {
+	return testAccVMConfig(api, "testvm", 1, 1024, 20) + fmt.Sprintf(`
+resource "idcloudhost_vm_disks" "test" {
+  size    = %d
+  vm_uuid = idcloudhost_vm.test.uuid
+}
+`, size)
+}
@@ -0,0 +1,442 @@
+package idcloudhost
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// firewall filters the traffic of the VMs it is attached to. Traffic not
+// allowed by one of its rules is dropped.
+type firewall struct {
+	UUID        string         `json:"uuid"`
+	Name        string         `json:"name"`
+	Description string         `json:"description"`
+	Rules       []firewallRule `json:"rules"`
+	VMUUIDs     []string       `json:"vm_uuids"`
+	CreatedAt   string         `json:"created_at"`
+	UpdatedAt   string         `json:"updated_at"`
+}
+
+// firewallRule allows traffic in one direction. PortStart and PortEnd are both
+// zero when the rule applies to all ports.
+type firewallRule struct {
+	UUID        string `json:"uuid"`
+	Direction   string `json:"direction"`
+	Protocol    string `json:"protocol"`
+	PortStart   int    `json:"port_start"`
+	PortEnd     int    `json:"port_end"`
+	CIDR        string `json:"cidr"`
+	Description string `json:"description"`
+}
+
+var (
+	firewallDirections = []string{"egress", "ingress"}
+	firewallProtocols  = []string{"all", "icmp", "tcp", "udp"}
+)
+
+// firewallRuleSchema is shared by the inline rule blocks of idcloudhost_firewall
+// and the idcloudhost_firewall_rule resource.
+func firewallRuleSchema(forceNew bool) map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		"cidr": {
+			Type:         schema.TypeString,
+			Required:     true,
+			ForceNew:     forceNew,
+			ValidateFunc: validateCIDR,
+		},
+		"description": {
+			Type:     schema.TypeString,
+			Optional: true,
+			ForceNew: forceNew,
+		},
+		"direction": {
+			Type:     schema.TypeString,
+			Required: true,
+			ForceNew: forceNew,
+			ValidateFunc: func(val interface{}, key string) (warns []string, errs []error) {
+				v := val.(string)
+				if !containsString(firewallDirections, v) {
+					errs = append(errs, fmt.Errorf("%q must be one of %s, got: %s", key, strings.Join(firewallDirections, ", "), v))
+				}
+				return
+			},
+		},
+		"port_range": {
+			Type:     schema.TypeString,
+			Optional: true,
+			ForceNew: forceNew,
+			ValidateFunc: func(val interface{}, key string) (warns []string, errs []error) {
+				if _, _, err := parsePortRange(val.(string)); err != nil {
+					errs = append(errs, fmt.Errorf("%q %s", key, err))
+				}
+				return
+			},
+		},
+		"protocol": {
+			Type:     schema.TypeString,
+			Required: true,
+			ForceNew: forceNew,
+			ValidateFunc: func(val interface{}, key string) (warns []string, errs []error) {
+				v := val.(string)
+				if !containsString(firewallProtocols, v) {
+					errs = append(errs, fmt.Errorf("%q must be one of %s, got: %s", key, strings.Join(firewallProtocols, ", "), v))
+				}
+				return
+			},
+		},
+	}
+}
+
+func resourceFirewall() *schema.Resource {
+	ruleSchema := firewallRuleSchema(false)
+	ruleSchema["uuid"] = &schema.Schema{
+		Type:     schema.TypeString,
+		Computed: true,
+	}
+
+	return &schema.Resource{
+		CreateContext: resourceFirewallCreate,
+		ReadContext:   resourceFirewallRead,
+		UpdateContext: resourceFirewallUpdate,
+		DeleteContext: resourceFirewallDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: importStateWithRegion(1),
+		},
+		CustomizeDiff: resourceFirewallCustomizeDiff,
+		Schema: map[string]*schema.Schema{
+			"created_at": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"description": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"name": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"region": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+				ForceNew: true,
+			},
+			// rule is computed so rules of idcloudhost_firewall_rule resources
+			// are kept when it is omitted, attribute syntax lets rule = []
+			// remove every rule
+			"rule": {
+				Type:       schema.TypeSet,
+				Optional:   true,
+				Computed:   true,
+				ConfigMode: schema.SchemaConfigModeAttr,
+				Set:        firewallRuleHash,
+				Elem: &schema.Resource{
+					Schema: ruleSchema,
+				},
+			},
+			"updated_at": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"uuid": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"vm_uuids": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+		},
+	}
+}
+
+func resourceFirewallCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	for _, r := range d.Get("rule").(*schema.Set).List() {
+		rule := r.(map[string]interface{})
+		if err := validateFirewallRulePorts(rule["protocol"].(string), rule["port_range"].(string)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func resourceFirewallCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	cfg := m.(*Config)
+	region := cfg.resourceRegion(d)
+
+	form := url.Values{
+		"name":        {d.Get("name").(string)},
+		"description": {d.Get("description").(string)},
+	}
+	var fw firewall
+	if err := cfg.apiRequest(ctx, http.MethodPost, fmt.Sprintf("%s/network/firewall", region), form, &fw); err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Unable to create firewall",
+			Detail:   fmt.Sprint(err),
+		})
+		return diags
+	}
+	d.SetId(fw.UUID)
+
+	for _, r := range d.Get("rule").(*schema.Set).List() {
+		if _, err := createFirewallRule(ctx, cfg, region, fw.UUID, r.(map[string]interface{})); err != nil {
+			diags = append(diags, diag.Diagnostic{
+				Severity: diag.Error,
+				Summary:  "Unable to create firewall rule",
+				Detail:   fmt.Sprint(err),
+			})
+			return diags
+		}
+	}
+
+	return resourceFirewallRead(ctx, d, m)
+}
+
+func resourceFirewallRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	cfg := m.(*Config)
+	region := cfg.resourceRegion(d)
+
+	fw, err := getFirewall(ctx, cfg, region, d.Id())
+	if isNotFoundError(err) {
+		log.Printf("[WARN] Firewall %s not found, removing from state", d.Id())
+		d.SetId("")
+		return diags
+	}
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Unable to get firewall",
+			Detail:   fmt.Sprint(err),
+		})
+		return diags
+	}
+
+	vmUUIDs := fw.VMUUIDs
+	if vmUUIDs == nil {
+		vmUUIDs = []string{}
+	}
+	values := map[string]interface{}{
+		"created_at":  fw.CreatedAt,
+		"description": fw.Description,
+		"name":        fw.Name,
+		"region":      region,
+		"rule":        flattenFirewallRules(fw.Rules),
+		"updated_at":  fw.UpdatedAt,
+		"uuid":        fw.UUID,
+		"vm_uuids":    vmUUIDs,
+	}
+	for key, value := range values {
+		if err := d.Set(key, value); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+	return diags
+}
+
+func resourceFirewallUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	cfg := m.(*Config)
+	region := cfg.resourceRegion(d)
+
+	if d.HasChanges("name", "description") {
+		form := url.Values{
+			"name":        {d.Get("name").(string)},
+			"description": {d.Get("description").(string)},
+		}
+		path := fmt.Sprintf("%s/network/firewall/%s", region, url.PathEscape(d.Id()))
+		if err := cfg.apiRequest(ctx, http.MethodPatch, path, form, nil); err != nil {
+			diags = append(diags, diag.Diagnostic{
+				Severity: diag.Error,
+				Summary:  "Unable to update firewall",
+				Detail:   fmt.Sprint(err),
+			})
+			return diags
+		}
+	}
+
+	// rules cannot be modified, changed rules are deleted and created again
+	if d.HasChange("rule") {
+		oldRules, newRules := d.GetChange("rule")
+		removed := oldRules.(*schema.Set).Difference(newRules.(*schema.Set))
+		added := newRules.(*schema.Set).Difference(oldRules.(*schema.Set))
+
+		for _, r := range removed.List() {
+			ruleUUID := r.(map[string]interface{})["uuid"].(string)
+			if err := deleteFirewallRule(ctx, cfg, region, d.Id(), ruleUUID); err != nil && !isNotFoundError(err) {
+				diags = append(diags, diag.Diagnostic{
+					Severity: diag.Error,
+					Summary:  "Unable to delete firewall rule",
+					Detail:   fmt.Sprint(err),
+				})
+				return diags
+			}
+		}
+		for _, r := range added.List() {
+			if _, err := createFirewallRule(ctx, cfg, region, d.Id(), r.(map[string]interface{})); err != nil {
+				diags = append(diags, diag.Diagnostic{
+					Severity: diag.Error,
+					Summary:  "Unable to create firewall rule",
+					Detail:   fmt.Sprint(err),
+				})
+				return diags
+			}
+		}
+	}
+
+	return resourceFirewallRead(ctx, d, m)
+}
+
+func resourceFirewallDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	cfg := m.(*Config)
+
+	path := fmt.Sprintf("%s/network/firewall/%s", cfg.resourceRegion(d), url.PathEscape(d.Id()))
+	err := cfg.apiRequest(ctx, http.MethodDelete, path, nil, nil)
+	if err != nil && !isNotFoundError(err) {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Unable to delete firewall",
+			Detail:   fmt.Sprint(err),
+		})
+		return diags
+	}
+	d.SetId("")
+	return diags
+}
+
+func getFirewall(ctx context.Context, cfg *Config, region string, uuid string) (*firewall, error) {
+	var fw firewall
+	path := fmt.Sprintf("%s/network/firewall/%s", region, url.PathEscape(uuid))
+	if err := cfg.apiRequest(ctx, http.MethodGet, path, nil, &fw); err != nil {
+		return nil, err
+	}
+	return &fw, nil
+}
+
+// createFirewallRule adds a rule given in the firewallRuleSchema format to a
+// firewall.
+func createFirewallRule(ctx context.Context, cfg *Config, region string, firewallUUID string, rule map[string]interface{}) (*firewallRule, error) {
+	portStart, portEnd, err := parsePortRange(rule["port_range"].(string))
+	if err != nil {
+		return nil, err
+	}
+	form := url.Values{
+		"direction":   {rule["direction"].(string)},
+		"protocol":    {rule["protocol"].(string)},
+		"port_start":  {strconv.Itoa(portStart)},
+		"port_end":    {strconv.Itoa(portEnd)},
+		"cidr":        {rule["cidr"].(string)},
+		"description": {rule["description"].(string)},
+	}
+	var created firewallRule
+	path := fmt.Sprintf("%s/network/firewall/%s/rules", region, url.PathEscape(firewallUUID))
+	if err := cfg.apiRequest(ctx, http.MethodPost, path, form, &created); err != nil {
+		return nil, err
+	}
+	return &created, nil
+}
+
+func deleteFirewallRule(ctx context.Context, cfg *Config, region string, firewallUUID string, ruleUUID string) error {
+	path := fmt.Sprintf("%s/network/firewall/%s/rules/%s", region, url.PathEscape(firewallUUID), url.PathEscape(ruleUUID))
+	return cfg.apiRequest(ctx, http.MethodDelete, path, nil, nil)
+}
+
+func flattenFirewallRule(rule *firewallRule) map[string]interface{} {
+	return map[string]interface{}{
+		"cidr":        rule.CIDR,
+		"description": rule.Description,
+		"direction":   rule.Direction,
+		"port_range":  formatPortRange(rule.PortStart, rule.PortEnd),
+		"protocol":    rule.Protocol,
+		"uuid":        rule.UUID,
+	}
+}
+
+func flattenFirewallRules(rules []firewallRule) []interface{} {
+	ruleList := []interface{}{}
+	for i := range rules {
+		ruleList = append(ruleList, flattenFirewallRule(&rules[i]))
+	}
+	return ruleList
+}
+
+// firewallRuleHash identifies inline rules by their content, leaving out the
+// UUID assigned by the API so configured rules match the ones read back.
+func firewallRuleHash(v interface{}) int {
+	rule := v.(map[string]interface{})
+	var buf bytes.Buffer
+	for _, key := range []string{"direction", "protocol", "port_range", "cidr", "description"} {
+		if value, ok := rule[key]; ok {
+			buf.WriteString(fmt.Sprintf("%s-", value))
+		}
+	}
+	return schema.HashString(buf.String())
+}
+
+// parsePortRange parses a port range such as "22" or "8000-8080". An empty
+// range means all ports and is returned as 0, 0.
+func parsePortRange(portRange string) (int, int, error) {
+	if portRange == "" {
+		return 0, 0, nil
+	}
+	bounds := strings.SplitN(portRange, "-", 2)
+	var ports []int
+	for _, bound := range bounds {
+		port, err := strconv.Atoi(bound)
+		if err != nil || port < 1 || port > 65535 {
+			return 0, 0, fmt.Errorf("must be a port such as \"22\" or a port range such as \"8000-8080\" between 1 and 65535, got: %s", portRange)
+		}
+		ports = append(ports, port)
+	}
+	if len(ports) == 1 {
+		return ports[0], ports[0], nil
+	}
+	if ports[0] > ports[1] {
+		return 0, 0, fmt.Errorf("must start with the lower port, got: %s", portRange)
+	}
+	return ports[0], ports[1], nil
+}
+
+func formatPortRange(portStart int, portEnd int) string {
+	switch {
+	case portStart == 0 && portEnd == 0:
+		return ""
+	case portStart == portEnd:
+		return strconv.Itoa(portStart)
+	default:
+		return fmt.Sprintf("%d-%d", portStart, portEnd)
+	}
+}
+
+// validateFirewallRulePorts rejects port ranges on protocols without ports.
+func validateFirewallRulePorts(protocol string, portRange string) error {
+	if portRange != "" && protocol != "tcp" && protocol != "udp" {
+		return fmt.Errorf("port_range %q cannot be set for protocol %q, only for tcp and udp", portRange, protocol)
+	}
+	return nil
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,146 @@
+package idcloudhost
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func resourceFirewallAttachment() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceFirewallAttachmentCreate,
+		ReadContext:   resourceFirewallAttachmentRead,
+		UpdateContext: resourceFirewallAttachmentUpdate,
+		DeleteContext: resourceFirewallAttachmentDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: importStateWithRegion(1),
+		},
+		Schema: map[string]*schema.Schema{
+			"firewall_uuid": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"region": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+				ForceNew: true,
+			},
+			"vm_uuids": {
+				Type:     schema.TypeSet,
+				Required: true,
+				MinItems: 1,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+		},
+	}
+}
+
+func resourceFirewallAttachmentCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	cfg := m.(*Config)
+	firewallUUID := d.Get("firewall_uuid").(string)
+
+	if err := attachFirewall(ctx, cfg, cfg.resourceRegion(d), firewallUUID, d.Get("vm_uuids").(*schema.Set).List()); err != nil {
+		return diag.FromErr(err)
+	}
+	d.SetId(firewallUUID)
+
+	return resourceFirewallAttachmentRead(ctx, d, m)
+}
+
+func resourceFirewallAttachmentRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	cfg := m.(*Config)
+	region := cfg.resourceRegion(d)
+
+	fw, err := getFirewall(ctx, cfg, region, d.Id())
+	if isNotFoundError(err) {
+		log.Printf("[WARN] Firewall %s not found, removing attachment from state", d.Id())
+		d.SetId("")
+		return diags
+	}
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Unable to get firewall",
+			Detail:   fmt.Sprint(err),
+		})
+		return diags
+	}
+	if len(fw.VMUUIDs) == 0 {
+		log.Printf("[WARN] Firewall %s is not attached to any VM, removing attachment from state", d.Id())
+		d.SetId("")
+		return diags
+	}
+
+	if err := d.Set("firewall_uuid", fw.UUID); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("vm_uuids", fw.VMUUIDs); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("region", region); err != nil {
+		return diag.FromErr(err)
+	}
+	return diags
+}
+
+func resourceFirewallAttachmentUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	cfg := m.(*Config)
+	region := cfg.resourceRegion(d)
+
+	if d.HasChange("vm_uuids") {
+		oldUUIDs, newUUIDs := d.GetChange("vm_uuids")
+		removed := oldUUIDs.(*schema.Set).Difference(newUUIDs.(*schema.Set))
+		added := newUUIDs.(*schema.Set).Difference(oldUUIDs.(*schema.Set))
+
+		if err := detachFirewall(ctx, cfg, region, d.Id(), removed.List()); err != nil {
+			return diag.FromErr(err)
+		}
+		if err := attachFirewall(ctx, cfg, region, d.Id(), added.List()); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	return resourceFirewallAttachmentRead(ctx, d, m)
+}
+
+func resourceFirewallAttachmentDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	cfg := m.(*Config)
+
+	if err := detachFirewall(ctx, cfg, cfg.resourceRegion(d), d.Id(), d.Get("vm_uuids").(*schema.Set).List()); err != nil {
+		return diag.FromErr(err)
+	}
+	d.SetId("")
+	return nil
+}
+
+func attachFirewall(ctx context.Context, cfg *Config, region string, firewallUUID string, vmUUIDs []interface{}) error {
+	path := fmt.Sprintf("%s/network/firewall/%s/vms", region, url.PathEscape(firewallUUID))
+	for _, vmUUID := range vmUUIDs {
+		if err := cfg.apiRequest(ctx, http.MethodPost, path, url.Values{"vm_uuid": {vmUUID.(string)}}, nil); err != nil {
+			return fmt.Errorf("unable to attach firewall %s to VM %s: %s", firewallUUID, vmUUID, err)
+		}
+	}
+	return nil
+}
+
+// detachFirewall detaches the firewall from the VMs, ignoring VMs that do not
+// exist anymore.
+func detachFirewall(ctx context.Context, cfg *Config, region string, firewallUUID string, vmUUIDs []interface{}) error {
+	for _, vmUUID := range vmUUIDs {
+		path := fmt.Sprintf("%s/network/firewall/%s/vms/%s", region, url.PathEscape(firewallUUID), url.PathEscape(vmUUID.(string)))
+		if err := cfg.apiRequest(ctx, http.MethodDelete, path, nil, nil); err != nil && !isNotFoundError(err) {
+			return fmt.Errorf("unable to detach firewall %s from VM %s: %s", firewallUUID, vmUUID, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package idcloudhost
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestAccIdcloudhostFirewallAttachment_basic(t *testing.T) {
+	api := newFakeAPI(t)
+	web1 := api.addVM("web-1")
+	web2 := api.addVM("web-2")
+	resourceName := "idcloudhost_firewall_attachment.test"
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: testAccProviderFactories,
+		CheckDestroy:      testAccCheckFirewallDestroy(api),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccFirewallAttachmentConfig(api, web1.UUID),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckFirewallAttachedTo(api, resourceName, web1.UUID),
+					resource.TestCheckResourceAttr(resourceName, "vm_uuids.#", "1"),
+				),
+			},
+			{
+				Config: testAccFirewallAttachmentConfig(api, web1.UUID, web2.UUID),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckFirewallAttachedTo(api, resourceName, web1.UUID, web2.UUID),
+				),
+			},
+			{
+				Config: testAccFirewallAttachmentConfig(api, web2.UUID),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckFirewallAttachedTo(api, resourceName, web2.UUID),
+				),
+			},
+			{
+				ResourceName:      resourceName,
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func testAccCheckFirewallAttachedTo(api *fakeAPI, resourceName string, vmUUIDs ...string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[resourceName]
+		if !ok {
+			return fmt.Errorf("not found: %s", resourceName)
+		}
+		fw := api.firewall(rs.Primary.ID)
+		if fw == nil {
+			return fmt.Errorf("firewall %s does not exist in the API", rs.Primary.ID)
+		}
+		if len(fw.VMUUIDs) != len(vmUUIDs) {
+			return fmt.Errorf("firewall %s is attached to %v, expected %v", rs.Primary.ID, fw.VMUUIDs, vmUUIDs)
+		}
+		for _, vmUUID := range vmUUIDs {
+			if !containsString(fw.VMUUIDs, vmUUID) {
+				return fmt.Errorf("firewall %s is not attached to VM %s", rs.Primary.ID, vmUUID)
+			}
+		}
+		return nil
+	}
+}
+
+func testAccFirewallAttachmentConfig(api *fakeAPI, vmUUIDs ...string) string {
+	return api.providerConfig() + fmt.Sprintf(`
+resource "idcloudhost_firewall" "test" {
+  name = "web"
+}
+
+resource "idcloudhost_firewall_attachment" "test" {
+  firewall_uuid = idcloudhost_firewall.test.uuid
+  vm_uuids      = %s
+}
+`, hclStringList(vmUUIDs))
+}
+
+func hclStringList(values []string) string {
+	quoted := "["
+	for i, v := range values {
+		if i > 0 {
+			quoted += ", "
+		}
+		quoted += fmt.Sprintf("%q", v)
+	}
+	return quoted + "]"
+}
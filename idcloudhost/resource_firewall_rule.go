@@ -0,0 +1,133 @@
+package idcloudhost
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func resourceFirewallRule() *schema.Resource {
+	ruleSchema := firewallRuleSchema(true)
+	ruleSchema["firewall_uuid"] = &schema.Schema{
+		Type:     schema.TypeString,
+		Required: true,
+		ForceNew: true,
+	}
+	ruleSchema["region"] = &schema.Schema{
+		Type:     schema.TypeString,
+		Optional: true,
+		Computed: true,
+		ForceNew: true,
+	}
+	ruleSchema["uuid"] = &schema.Schema{
+		Type:     schema.TypeString,
+		Computed: true,
+	}
+
+	return &schema.Resource{
+		CreateContext: resourceFirewallRuleCreate,
+		ReadContext:   resourceFirewallRuleRead,
+		DeleteContext: resourceFirewallRuleDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: importStateWithRegion(2),
+		},
+		CustomizeDiff: func(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+			return validateFirewallRulePorts(d.Get("protocol").(string), d.Get("port_range").(string))
+		},
+		Schema: ruleSchema,
+	}
+}
+
+func resourceFirewallRuleCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	cfg := m.(*Config)
+
+	firewallUUID := d.Get("firewall_uuid").(string)
+	rule := map[string]interface{}{}
+	for _, key := range []string{"cidr", "description", "direction", "port_range", "protocol"} {
+		rule[key] = d.Get(key)
+	}
+	created, err := createFirewallRule(ctx, cfg, cfg.resourceRegion(d), firewallUUID, rule)
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Unable to create firewall rule",
+			Detail:   fmt.Sprint(err),
+		})
+		return diags
+	}
+	d.SetId(fmt.Sprintf("%s/%s", firewallUUID, created.UUID))
+
+	return resourceFirewallRuleRead(ctx, d, m)
+}
+
+func resourceFirewallRuleRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	cfg := m.(*Config)
+	region := cfg.resourceRegion(d)
+
+	ruleResourceId := strings.Split(d.Id(), "/")
+	if len(ruleResourceId) != 2 {
+		return diag.Errorf("invalid firewall rule ID %q, expected <firewall_uuid>/<rule_uuid>", d.Id())
+	}
+	firewallUUID, ruleUUID := ruleResourceId[0], ruleResourceId[1]
+
+	fw, err := getFirewall(ctx, cfg, region, firewallUUID)
+	if isNotFoundError(err) {
+		log.Printf("[WARN] Firewall %s of rule %s not found, removing rule from state", firewallUUID, ruleUUID)
+		d.SetId("")
+		return diags
+	}
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Unable to get firewall",
+			Detail:   fmt.Sprint(err),
+		})
+		return diags
+	}
+
+	var rule *firewallRule
+	for i := range fw.Rules {
+		if fw.Rules[i].UUID == ruleUUID {
+			rule = &fw.Rules[i]
+			break
+		}
+	}
+	if rule == nil {
+		log.Printf("[WARN] Rule %s not found in firewall %s, removing from state", ruleUUID, firewallUUID)
+		d.SetId("")
+		return diags
+	}
+
+	values := flattenFirewallRule(rule)
+	values["firewall_uuid"] = firewallUUID
+	values["region"] = region
+	for key, value := range values {
+		if err := d.Set(key, value); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+	return diags
+}
+
+func resourceFirewallRuleDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	cfg := m.(*Config)
+
+	err := deleteFirewallRule(ctx, cfg, cfg.resourceRegion(d), d.Get("firewall_uuid").(string), d.Get("uuid").(string))
+	if err != nil && !isNotFoundError(err) {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Unable to delete firewall rule",
+			Detail:   fmt.Sprint(err),
+		})
+		return diags
+	}
+	d.SetId("")
+	return diags
+}
@@ -0,0 +1,56 @@
+package idcloudhost
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccIdcloudhostFirewallRule_basic(t *testing.T) {
+	api := newFakeAPI(t)
+	resourceName := "idcloudhost_firewall_rule.https"
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: testAccProviderFactories,
+		CheckDestroy:      testAccCheckFirewallDestroy(api),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccFirewallRuleConfig(api, "443"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckFirewallRules(api, "idcloudhost_firewall.test", 1),
+					resource.TestCheckResourceAttr(resourceName, "port_range", "443"),
+					resource.TestCheckResourceAttrPair(resourceName, "firewall_uuid", "idcloudhost_firewall.test", "uuid"),
+				),
+			},
+			{
+				Config: testAccFirewallRuleConfig(api, "8443"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckFirewallRules(api, "idcloudhost_firewall.test", 1),
+					resource.TestCheckResourceAttr(resourceName, "port_range", "8443"),
+				),
+			},
+			{
+				ResourceName:      resourceName,
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func testAccFirewallRuleConfig(api *fakeAPI, port string) string {
+	return api.providerConfig() + fmt.Sprintf(`
+resource "idcloudhost_firewall" "test" {
+  name = "web"
+}
+
+resource "idcloudhost_firewall_rule" "https" {
+  firewall_uuid = idcloudhost_firewall.test.uuid
+  direction     = "ingress"
+  protocol      = "tcp"
+  port_range    = %q
+  cidr          = "0.0.0.0/0"
+}
+`, port)
+}
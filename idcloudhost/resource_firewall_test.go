@@ -0,0 +1,226 @@
+package idcloudhost
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestAccIdcloudhostFirewall_basic(t *testing.T) {
+	api := newFakeAPI(t)
+	resourceName := "idcloudhost_firewall.test"
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: testAccProviderFactories,
+		CheckDestroy:      testAccCheckFirewallDestroy(api),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccFirewallConfig(api, "web", "22"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckFirewallRules(api, resourceName, 2),
+					resource.TestCheckResourceAttr(resourceName, "name", "web"),
+					resource.TestCheckResourceAttr(resourceName, "rule.#", "2"),
+					resource.TestCheckTypeSetElemNestedAttrs(resourceName, "rule.*", map[string]string{
+						"direction":  "ingress",
+						"protocol":   "tcp",
+						"port_range": "22",
+						"cidr":       "10.0.0.0/8",
+					}),
+				),
+			},
+			{
+				Config: testAccFirewallConfig(api, "web-renamed", "2222"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckFirewallRules(api, resourceName, 2),
+					resource.TestCheckResourceAttr(resourceName, "name", "web-renamed"),
+					resource.TestCheckTypeSetElemNestedAttrs(resourceName, "rule.*", map[string]string{
+						"port_range": "2222",
+					}),
+				),
+			},
+			{
+				ResourceName:      resourceName,
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+			{
+				Config: testAccFirewallConfigRules(api, "web-renamed", "rule = []"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckFirewallRules(api, resourceName, 0),
+					resource.TestCheckResourceAttr(resourceName, "rule.#", "0"),
+				),
+			},
+		},
+	})
+}
+
+// TestResourceFirewall_removeRules checks that removing the last rule deletes
+// it, while omitting rule keeps rules managed by idcloudhost_firewall_rule.
+func TestResourceFirewall_removeRules(t *testing.T) {
+	api := newFakeAPI(t)
+	cfg := api.config()
+	r := resourceFirewall()
+	ruleType := r.CoreConfigSchema().ImpliedType().AttributeType("rule")
+	firewallConfig := func(rules cty.Value) *terraform.ResourceConfig {
+		return testResourceConfigShimmed(t, r, cty.ObjectVal(map[string]cty.Value{
+			"name": cty.StringVal("web"),
+			"rule": rules,
+		}))
+	}
+	sshRule := cty.ObjectVal(map[string]cty.Value{
+		"direction":   cty.StringVal("ingress"),
+		"protocol":    cty.StringVal("tcp"),
+		"port_range":  cty.StringVal("22"),
+		"cidr":        cty.StringVal("10.0.0.0/8"),
+		"description": cty.NullVal(cty.String),
+		"uuid":        cty.NullVal(cty.String),
+	})
+
+	state, diags := testResourceApplyConfig(t, r, nil, firewallConfig(cty.SetVal([]cty.Value{sshRule})), cfg)
+	if diags.HasError() {
+		t.Fatalf("unable to create firewall: %v", diags)
+	}
+	if got := len(api.firewall(state.ID).Rules); got != 1 {
+		t.Fatalf("got %d rules, want 1", got)
+	}
+
+	state, diags = testResourceApplyConfig(t, r, state, firewallConfig(cty.NullVal(ruleType)), cfg)
+	if diags.HasError() {
+		t.Fatalf("unable to update firewall: %v", diags)
+	}
+	if got := len(api.firewall(state.ID).Rules); got != 1 {
+		t.Errorf("got %d rules after omitting rule, want 1", got)
+	}
+
+	state, diags = testResourceApplyConfig(t, r, state, firewallConfig(cty.SetValEmpty(ruleType.ElementType())), cfg)
+	if diags.HasError() {
+		t.Fatalf("unable to remove firewall rules: %v", diags)
+	}
+	if got := len(api.firewall(state.ID).Rules); got != 0 {
+		t.Errorf("got %d rules after removing the last one, want 0", got)
+	}
+	if got := state.Attributes["rule.#"]; got != "0" {
+		t.Errorf("got rule.# %s in the state, want 0", got)
+	}
+}
+
+func TestAccIdcloudhostFirewall_invalidRule(t *testing.T) {
+	api := newFakeAPI(t)
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: testAccProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: api.providerConfig() + `
+resource "idcloudhost_firewall" "test" {
+  name = "web"
+  rule {
+    direction  = "ingress"
+    protocol   = "icmp"
+    port_range = "22"
+    cidr       = "0.0.0.0/0"
+  }
+}
+`,
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile(`cannot be set for protocol "icmp"`),
+			},
+		},
+	})
+}
+
+func TestParsePortRange(t *testing.T) {
+	cases := []struct {
+		portRange      string
+		start, end     int
+		expectingError bool
+	}{
+		{"", 0, 0, false},
+		{"22", 22, 22, false},
+		{"8000-8080", 8000, 8080, false},
+		{"8080-8000", 0, 0, true},
+		{"0", 0, 0, true},
+		{"65536", 0, 0, true},
+		{"http", 0, 0, true},
+	}
+	for _, c := range cases {
+		start, end, err := parsePortRange(c.portRange)
+		if (err != nil) != c.expectingError {
+			t.Errorf("parsePortRange(%q) error = %v, expecting error: %t", c.portRange, err, c.expectingError)
+			continue
+		}
+		if start != c.start || end != c.end {
+			t.Errorf("parsePortRange(%q) = %d, %d, want %d, %d", c.portRange, start, end, c.start, c.end)
+		}
+		if err == nil && formatPortRange(start, end) != c.portRange {
+			t.Errorf("formatPortRange(%d, %d) = %q, want %q", start, end, formatPortRange(start, end), c.portRange)
+		}
+	}
+}
+
+func testAccCheckFirewallRules(api *fakeAPI, resourceName string, count int) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[resourceName]
+		if !ok {
+			return fmt.Errorf("not found: %s", resourceName)
+		}
+		fw := api.firewall(rs.Primary.ID)
+		if fw == nil {
+			return fmt.Errorf("firewall %s does not exist in the API", rs.Primary.ID)
+		}
+		if len(fw.Rules) != count {
+			return fmt.Errorf("firewall %s has %d rules in the API, expected %d", rs.Primary.ID, len(fw.Rules), count)
+		}
+		return nil
+	}
+}
+
+func testAccCheckFirewallDestroy(api *fakeAPI) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		for _, rs := range s.RootModule().Resources {
+			if rs.Type != "idcloudhost_firewall" {
+				continue
+			}
+			if api.firewall(rs.Primary.ID) != nil {
+				return fmt.Errorf("firewall %s still exists", rs.Primary.ID)
+			}
+		}
+		return nil
+	}
+}
+
+// testAccFirewallConfigRules creates a firewall with the given rule argument.
+func testAccFirewallConfigRules(api *fakeAPI, name string, rules string) string {
+	return api.providerConfig() + fmt.Sprintf(`
+resource "idcloudhost_firewall" "test" {
+  name = %q
+  %s
+}
+`, name, rules)
+}
+
+func testAccFirewallConfig(api *fakeAPI, name string, sshPort string) string {
+	return api.providerConfig() + fmt.Sprintf(`
+resource "idcloudhost_firewall" "test" {
+  name = %q
+
+  rule {
+    direction  = "ingress"
+    protocol   = "tcp"
+    port_range = %q
+    cidr       = "10.0.0.0/8"
+  }
+
+  rule {
+    direction   = "egress"
+    protocol    = "all"
+    cidr        = "0.0.0.0/0"
+    description = "allow all outbound"
+  }
+}
+`, name, sshPort)
+}
@@ -3,9 +3,9 @@ package idcloudhost
 import (
 	"context"
 	"fmt"
+	"log"
 	"time"
 
-	idcloudhostAPI "github.com/bapung/idcloudhost-go-client-library/idcloudhost/api"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
@@ -20,7 +20,7 @@ func resourceFloatingIP() *schema.Resource {
 			Create: schema.DefaultTimeout(5 * time.Minute),
 		},
 		Importer: &schema.ResourceImporter{
-			StateContext: schema.ImportStatePassthroughContext,
+			StateContext: importStateWithRegion(1),
 		},
 		Schema: map[string]*schema.Schema{
 			"id": {
@@ -37,7 +37,8 @@ func resourceFloatingIP() *schema.Resource {
 			},
 			"billing_account_id": {
 				Type:     schema.TypeInt,
-				Required: true,
+				Optional: true,
+				Computed: true,
 			},
 			"type": {
 				Type:     schema.TypeString,
@@ -63,6 +64,12 @@ func resourceFloatingIP() *schema.Resource {
 				Type:     schema.TypeString,
 				Computed: true,
 			},
+			"region": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+				ForceNew: true,
+			},
 			"assigned_to": {
 				Type:     schema.TypeString,
 				Optional: true,
@@ -73,13 +80,20 @@ func resourceFloatingIP() *schema.Resource {
 }
 func resourceFloatingIPCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	var diags diag.Diagnostics
-	c := m.(*idcloudhostAPI.APIClient)
+	cfg := m.(*Config)
+	c, err := cfg.Client(cfg.resourceRegion(d))
+	if err != nil {
+		return diag.FromErr(err)
+	}
 	fipApi := c.FloatingIP
 
-	billingAccountId := d.Get("billing_account_id").(int)
+	billingAccountId, err := cfg.billingAccountID(ctx, d)
+	if err != nil {
+		return diag.FromErr(err)
+	}
 	name := d.Get("name").(string)
 	assignedUuid := d.Get("assigned_to").(string)
-	err := fipApi.Create(name, billingAccountId)
+	err = fipApi.Create(name, billingAccountId)
 	if err != nil {
 		diags = append(diags, diag.Diagnostic{})
 		return diags
@@ -103,11 +117,15 @@ func resourceFloatingIPCreate(ctx context.Context, d *schema.ResourceData, m int
 }
 
 func resourceFloatingIPRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	c := m.(*idcloudhostAPI.APIClient)
+	cfg := m.(*Config)
 	var diags diag.Diagnostics
 	ipAddress := d.Id()
-	fipApi := c.FloatingIP
-	err := fipApi.Get(ipAddress)
+	fip, err := getFloatingIP(ctx, cfg, cfg.resourceRegion(d), ipAddress)
+	if isNotFoundError(err) {
+		log.Printf("[WARN] Floating IP %s not found, removing from state", ipAddress)
+		d.SetId("")
+		return diags
+	}
 	if err != nil {
 		diags = append(diags, diag.Diagnostic{
 			Severity: diag.Error,
@@ -117,7 +135,7 @@ func resourceFloatingIPRead(ctx context.Context, d *schema.ResourceData, m inter
 		return diags
 	}
 
-	err = setFloatingIP(d, fipApi.FloatingIP)
+	err = setFloatingIP(d, fip)
 	if err != nil {
 		diags = append(diags, diag.Diagnostic{
 			Severity: diag.Error,
@@ -126,13 +144,20 @@ func resourceFloatingIPRead(ctx context.Context, d *schema.ResourceData, m inter
 		})
 		return diags
 	}
+	if err := d.Set("region", cfg.resourceRegion(d)); err != nil {
+		return diag.FromErr(err)
+	}
 
 	return diags
 }
 
 func resourceFloatingIPUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	var diags diag.Diagnostics
-	c := m.(*idcloudhostAPI.APIClient)
+	cfg := m.(*Config)
+	c, err := cfg.Client(cfg.resourceRegion(d))
+	if err != nil {
+		return diag.FromErr(err)
+	}
 	fipApi := c.FloatingIP
 	ipAddress := d.Id()
 	if d.HasChanges("billing_account_id", "name") {
@@ -149,9 +174,9 @@ func resourceFloatingIPUpdate(ctx context.Context, d *schema.ResourceData, m int
 		var err error
 		assignedUuid := d.Get("assigned_to").(string)
 		if assignedUuid != "" {
-			err = fipApi.Assign(fipApi.FloatingIP.Address, assignedUuid)
+			err = fipApi.Assign(ipAddress, assignedUuid)
 		} else {
-			err = fipApi.Unassign(fipApi.FloatingIP.Address)
+			err = fipApi.Unassign(ipAddress)
 		}
 		if err != nil {
 			diags = append(diags, diag.Diagnostic{
@@ -170,10 +195,14 @@ func resourceFloatingIPUpdate(ctx context.Context, d *schema.ResourceData, m int
 
 func resourceFloatingIPDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	var diags diag.Diagnostics
-	c := m.(*idcloudhostAPI.APIClient)
+	cfg := m.(*Config)
+	c, err := cfg.Client(cfg.resourceRegion(d))
+	if err != nil {
+		return diag.FromErr(err)
+	}
 	ipAddress := d.Id()
 	fipApi := c.FloatingIP
-	err := fipApi.Delete(ipAddress)
+	err = fipApi.Delete(ipAddress)
 	if err != nil {
 		return diag.FromErr(err)
 	}
@@ -0,0 +1,86 @@
+package idcloudhost
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestAccIdcloudhostFloatingIP_basic(t *testing.T) {
+	api := newFakeAPI(t)
+	resourceName := "idcloudhost_floating_ip.test"
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: testAccProviderFactories,
+		CheckDestroy:      testAccCheckFloatingIPDestroy(api),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccFloatingIPConfig(api, "testip", ""),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckFloatingIPExists(api, resourceName),
+					resource.TestCheckResourceAttr(resourceName, "name", "testip"),
+					resource.TestCheckResourceAttr(resourceName, "assigned_to", ""),
+					resource.TestCheckResourceAttrSet(resourceName, "address"),
+				),
+			},
+			{
+				Config: testAccFloatingIPConfig(api, "testip-renamed", "idcloudhost_vm.test.uuid"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckFloatingIPExists(api, resourceName),
+					resource.TestCheckResourceAttr(resourceName, "name", "testip-renamed"),
+					resource.TestCheckResourceAttrPair(resourceName, "assigned_to", "idcloudhost_vm.test", "uuid"),
+				),
+			},
+			{
+				Config: testAccFloatingIPConfig(api, "testip-renamed", ""),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(resourceName, "assigned_to", ""),
+				),
+			},
+		},
+	})
+}
+
+func testAccCheckFloatingIPExists(api *fakeAPI, resourceName string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[resourceName]
+		if !ok {
+			return fmt.Errorf("not found: %s", resourceName)
+		}
+		if api.floatingIP(rs.Primary.ID) == nil {
+			return fmt.Errorf("floating IP %s does not exist in the API", rs.Primary.ID)
+		}
+		return nil
+	}
+}
+
+func testAccCheckFloatingIPDestroy(api *fakeAPI) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		for _, rs := range s.RootModule().Resources {
+			if rs.Type != "idcloudhost_floating_ip" {
+				continue
+			}
+			if api.floatingIP(rs.Primary.ID) != nil {
+				return fmt.Errorf("floating IP %s still exists", rs.Primary.ID)
+			}
+		}
+		return nil
+	}
+}
+
+// testAccFloatingIPConfig creates a VM next to the floating IP, assignedTo is
+// an HCL expression or empty to leave the floating IP unassigned.
+func testAccFloatingIPConfig(api *fakeAPI, name string, assignedTo string) string {
+	if assignedTo == "" {
+		assignedTo = `""`
+	}
+	return testAccVMConfig(api, "testvm", 1, 1024, 20) + fmt.Sprintf(`
+resource "idcloudhost_floating_ip" "test" {
+  name               = %q
+  billing_account_id = %d
+  assigned_to        = %s
+}
+`, name, fakeAPIBillingAccountID, assignedTo)
+}
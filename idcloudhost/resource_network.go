@@ -0,0 +1,253 @@
+package idcloudhost
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"sort"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// network is a private network (VPC) VMs are attached to. Every region has a
+// default network, used for VMs created without network_uuid.
+type network struct {
+	UUID      string   `json:"uuid"`
+	Name      string   `json:"name"`
+	Subnet    string   `json:"subnet"`
+	IsDefault bool     `json:"is_default"`
+	VlanID    int      `json:"vlan_id"`
+	Type      string   `json:"type"`
+	VMUUIDs   []string `json:"vm_uuids"`
+	CreatedAt string   `json:"created_at"`
+	UpdatedAt string   `json:"updated_at"`
+}
+
+func resourceNetwork() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceNetworkCreate,
+		ReadContext:   resourceNetworkRead,
+		UpdateContext: resourceNetworkUpdate,
+		DeleteContext: resourceNetworkDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: importStateWithRegion(1),
+		},
+		CustomizeDiff: resourceNetworkCustomizeDiff,
+		Schema: map[string]*schema.Schema{
+			"cidr": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				ForceNew:     true,
+				ValidateFunc: validateCIDR,
+			},
+			"created_at": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"default": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Computed: true,
+			},
+			"name": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"region": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+				ForceNew: true,
+			},
+			"type": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"updated_at": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"uuid": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"vlan_id": {
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
+			"vm_uuids": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+		},
+	}
+}
+
+// resourceNetworkCustomizeDiff rejects unsetting default, the API can only
+// make another network the default one.
+func resourceNetworkCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	if d.Id() == "" || !d.HasChange("default") {
+		return nil
+	}
+	if !d.Get("default").(bool) {
+		return fmt.Errorf("network %s cannot stop being the default network, set default = true on another network instead", d.Id())
+	}
+	return nil
+}
+
+func resourceNetworkCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	cfg := m.(*Config)
+	region := cfg.resourceRegion(d)
+
+	form := url.Values{"name": {d.Get("name").(string)}}
+	if cidr, ok := d.GetOk("cidr"); ok {
+		form.Set("subnet", cidr.(string))
+	}
+	var n network
+	if err := cfg.apiRequest(ctx, http.MethodPost, fmt.Sprintf("%s/network/network", region), form, &n); err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Unable to create network",
+			Detail:   fmt.Sprint(err),
+		})
+		return diags
+	}
+	d.SetId(n.UUID)
+
+	if d.Get("default").(bool) {
+		if err := setDefaultNetwork(ctx, cfg, region, n.UUID); err != nil {
+			diags = append(diags, diag.Diagnostic{
+				Severity: diag.Error,
+				Summary:  "Unable to make network the default one",
+				Detail:   fmt.Sprint(err),
+			})
+			return diags
+		}
+	}
+
+	return resourceNetworkRead(ctx, d, m)
+}
+
+func resourceNetworkRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	cfg := m.(*Config)
+	region := cfg.resourceRegion(d)
+
+	var n network
+	err := cfg.apiRequest(ctx, http.MethodGet, fmt.Sprintf("%s/network/network/%s", region, url.PathEscape(d.Id())), nil, &n)
+	if isNotFoundError(err) {
+		log.Printf("[WARN] Network %s not found, removing from state", d.Id())
+		d.SetId("")
+		return diags
+	}
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Unable to get network",
+			Detail:   fmt.Sprint(err),
+		})
+		return diags
+	}
+
+	if err := setNetworkResource(d, &n); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("region", region); err != nil {
+		return diag.FromErr(err)
+	}
+	return diags
+}
+
+func resourceNetworkUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	cfg := m.(*Config)
+	region := cfg.resourceRegion(d)
+
+	if d.HasChange("name") {
+		path := fmt.Sprintf("%s/network/network/%s", region, url.PathEscape(d.Id()))
+		if err := cfg.apiRequest(ctx, http.MethodPatch, path, url.Values{"name": {d.Get("name").(string)}}, nil); err != nil {
+			diags = append(diags, diag.Diagnostic{
+				Severity: diag.Error,
+				Summary:  "Unable to rename network",
+				Detail:   fmt.Sprint(err),
+			})
+			return diags
+		}
+	}
+	if d.HasChange("default") && d.Get("default").(bool) {
+		if err := setDefaultNetwork(ctx, cfg, region, d.Id()); err != nil {
+			diags = append(diags, diag.Diagnostic{
+				Severity: diag.Error,
+				Summary:  "Unable to make network the default one",
+				Detail:   fmt.Sprint(err),
+			})
+			return diags
+		}
+	}
+
+	return resourceNetworkRead(ctx, d, m)
+}
+
+func resourceNetworkDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	cfg := m.(*Config)
+
+	path := fmt.Sprintf("%s/network/network/%s", cfg.resourceRegion(d), url.PathEscape(d.Id()))
+	err := cfg.apiRequest(ctx, http.MethodDelete, path, nil, nil)
+	if err != nil && !isNotFoundError(err) {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Unable to delete network",
+			Detail:   fmt.Sprintf("%s. The default network and networks with VMs attached cannot be deleted.", err),
+		})
+		return diags
+	}
+	d.SetId("")
+	return diags
+}
+
+func setDefaultNetwork(ctx context.Context, cfg *Config, region string, uuid string) error {
+	path := fmt.Sprintf("%s/network/network/%s/default", region, url.PathEscape(uuid))
+	return cfg.apiRequest(ctx, http.MethodPost, path, nil, nil)
+}
+
+// listNetworks returns the private networks in region, sorted by name and
+// UUID.
+func listNetworks(ctx context.Context, cfg *Config, region string) ([]network, error) {
+	var networks []network
+	if err := cfg.apiRequest(ctx, http.MethodGet, fmt.Sprintf("%s/network/networks", region), nil, &networks); err != nil {
+		return nil, err
+	}
+	sort.Slice(networks, func(i, j int) bool {
+		if networks[i].Name != networks[j].Name {
+			return networks[i].Name < networks[j].Name
+		}
+		return networks[i].UUID < networks[j].UUID
+	})
+	return networks, nil
+}
+
+// vmNetworkUUID returns the UUID of the network the VM is attached to, or an
+// empty string when it is not attached to any.
+func vmNetworkUUID(ctx context.Context, cfg *Config, region string, vmUUID string) (string, error) {
+	networks, err := listNetworks(ctx, cfg, region)
+	if err != nil {
+		return "", err
+	}
+	for _, n := range networks {
+		for _, uuid := range n.VMUUIDs {
+			if uuid == vmUUID {
+				return n.UUID, nil
+			}
+		}
+	}
+	return "", nil
+}
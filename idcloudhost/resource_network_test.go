@@ -0,0 +1,121 @@
+package idcloudhost
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestAccIdcloudhostNetwork_basic(t *testing.T) {
+	api := newFakeAPI(t)
+	resourceName := "idcloudhost_network.test"
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: testAccProviderFactories,
+		CheckDestroy:      testAccCheckNetworkDestroy(api),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccNetworkConfig(api, "staging", false),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckNetworkExists(api, resourceName),
+					resource.TestCheckResourceAttr(resourceName, "name", "staging"),
+					resource.TestCheckResourceAttr(resourceName, "cidr", "10.20.0.0/24"),
+					resource.TestCheckResourceAttr(resourceName, "default", "false"),
+					resource.TestCheckResourceAttr(resourceName, "region", "jkt01"),
+				),
+			},
+			{
+				Config: testAccNetworkConfig(api, "staging-renamed", false),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(resourceName, "name", "staging-renamed"),
+				),
+			},
+			{
+				ResourceName:      resourceName,
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func TestAccIdcloudhostNetwork_vm(t *testing.T) {
+	api := newFakeAPI(t)
+	resourceName := "idcloudhost_network.test"
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: testAccProviderFactories,
+		CheckDestroy:      testAccCheckNetworkDestroy(api),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccNetworkConfigVM(api),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrPair("idcloudhost_vm.test", "network_uuid", resourceName, "uuid"),
+				),
+			},
+			{
+				Config: testAccNetworkConfigVM(api),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(resourceName, "vm_uuids.#", "1"),
+					resource.TestCheckResourceAttrPair(resourceName, "vm_uuids.0", "idcloudhost_vm.test", "uuid"),
+				),
+			},
+		},
+	})
+}
+
+func testAccCheckNetworkExists(api *fakeAPI, resourceName string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[resourceName]
+		if !ok {
+			return fmt.Errorf("not found: %s", resourceName)
+		}
+		if api.network(rs.Primary.ID) == nil {
+			return fmt.Errorf("network %s does not exist in the API", rs.Primary.ID)
+		}
+		return nil
+	}
+}
+
+func testAccCheckNetworkDestroy(api *fakeAPI) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		for _, rs := range s.RootModule().Resources {
+			if rs.Type != "idcloudhost_network" {
+				continue
+			}
+			if api.network(rs.Primary.ID) != nil {
+				return fmt.Errorf("network %s still exists", rs.Primary.ID)
+			}
+		}
+		return nil
+	}
+}
+
+func testAccNetworkConfig(api *fakeAPI, name string, isDefault bool) string {
+	return api.providerConfig() + fmt.Sprintf(`
+resource "idcloudhost_network" "test" {
+  name    = %q
+  cidr    = "10.20.0.0/24"
+  default = %t
+}
+`, name, isDefault)
+}
+
+func testAccNetworkConfigVM(api *fakeAPI) string {
+	return testAccNetworkConfig(api, "staging", false) + fmt.Sprintf(`
+resource "idcloudhost_vm" "test" {
+  name               = "testvm"
+  os_name            = "ubuntu"
+  os_version         = "20.04"
+  disks              = 20
+  vcpu               = 1
+  memory             = 1024
+  username           = "example"
+  initial_password   = "Password123"
+  billing_account_id = %d
+  network_uuid       = idcloudhost_network.test.uuid
+}
+`, fakeAPIBillingAccountID)
+}
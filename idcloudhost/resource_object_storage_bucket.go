@@ -0,0 +1,207 @@
+package idcloudhost
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+const (
+	bucketACLPrivate    = "private"
+	bucketACLPublicRead = "public-read"
+)
+
+// objectStorageBucket is a bucket of the S3 compatible object storage. Buckets
+// are not bound to a region and are identified by their name.
+type objectStorageBucket struct {
+	Name             string `json:"name"`
+	BillingAccountID int    `json:"billing_account_id"`
+	ACL              string `json:"acl"`
+	Versioning       bool   `json:"versioning"`
+	SizeBytes        int    `json:"size_bytes"`
+	NumObjects       int    `json:"num_objects"`
+	CreatedAt        string `json:"created_at"`
+	ModifiedAt       string `json:"modified_at"`
+}
+
+// bucketNameRegexp follows the S3 bucket naming rules: 3 to 63 lowercase
+// letters, digits, dots and hyphens, starting and ending with a letter or digit.
+var bucketNameRegexp = regexp.MustCompile(`^[a-z0-9][a-z0-9.-]{1,61}[a-z0-9]$`)
+
+func resourceObjectStorageBucket() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceObjectStorageBucketCreate,
+		ReadContext:   resourceObjectStorageBucketRead,
+		UpdateContext: resourceObjectStorageBucketUpdate,
+		DeleteContext: resourceObjectStorageBucketDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
+		Schema: map[string]*schema.Schema{
+			"acl": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Default:  bucketACLPrivate,
+				ValidateFunc: func(val interface{}, key string) (warns []string, errs []error) {
+					v := val.(string)
+					if v != bucketACLPrivate && v != bucketACLPublicRead {
+						errs = append(errs, fmt.Errorf("%q must be either %q or %q, got: %s", key, bucketACLPrivate, bucketACLPublicRead, v))
+					}
+					return
+				},
+			},
+			"billing_account_id": {
+				Type:     schema.TypeInt,
+				Optional: true,
+				Computed: true,
+			},
+			"created_at": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"modified_at": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"name": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+				ValidateFunc: func(val interface{}, key string) (warns []string, errs []error) {
+					v := val.(string)
+					if !bucketNameRegexp.MatchString(v) {
+						errs = append(errs, fmt.Errorf("%q must be 3 to 63 lowercase letters, digits, dots or hyphens, starting and ending with a letter or digit, got: %s", key, v))
+					}
+					return
+				},
+			},
+			"num_objects": {
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
+			"size_bytes": {
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
+			"versioning": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+		},
+	}
+}
+
+func resourceObjectStorageBucketCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	cfg := m.(*Config)
+
+	billingAccountId, err := cfg.billingAccountID(ctx, d)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	form := url.Values{
+		"name":               {d.Get("name").(string)},
+		"billing_account_id": {strconv.Itoa(billingAccountId)},
+		"acl":                {d.Get("acl").(string)},
+		"versioning":         {strconv.FormatBool(d.Get("versioning").(bool))},
+	}
+	var bucket objectStorageBucket
+	if err := cfg.apiRequest(ctx, http.MethodPost, "storage/bucket", form, &bucket); err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Unable to create object storage bucket",
+			Detail:   fmt.Sprint(err),
+		})
+		return diags
+	}
+	d.SetId(bucket.Name)
+
+	return resourceObjectStorageBucketRead(ctx, d, m)
+}
+
+func resourceObjectStorageBucketRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	cfg := m.(*Config)
+
+	var bucket objectStorageBucket
+	err := cfg.apiRequest(ctx, http.MethodGet, "storage/bucket", url.Values{"name": {d.Id()}}, &bucket)
+	if isNotFoundError(err) {
+		log.Printf("[WARN] Object storage bucket %s not found, removing from state", d.Id())
+		d.SetId("")
+		return diags
+	}
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Unable to get object storage bucket",
+			Detail:   fmt.Sprint(err),
+		})
+		return diags
+	}
+
+	values := map[string]interface{}{
+		"acl":                bucket.ACL,
+		"billing_account_id": bucket.BillingAccountID,
+		"created_at":         bucket.CreatedAt,
+		"modified_at":        bucket.ModifiedAt,
+		"name":               bucket.Name,
+		"num_objects":        bucket.NumObjects,
+		"size_bytes":         bucket.SizeBytes,
+		"versioning":         bucket.Versioning,
+	}
+	for key, value := range values {
+		if err := d.Set(key, value); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+	return diags
+}
+
+func resourceObjectStorageBucketUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	cfg := m.(*Config)
+
+	if d.HasChanges("acl", "billing_account_id", "versioning") {
+		form := url.Values{
+			"name":               {d.Id()},
+			"billing_account_id": {strconv.Itoa(d.Get("billing_account_id").(int))},
+			"acl":                {d.Get("acl").(string)},
+			"versioning":         {strconv.FormatBool(d.Get("versioning").(bool))},
+		}
+		if err := cfg.apiRequest(ctx, http.MethodPatch, "storage/bucket", form, nil); err != nil {
+			diags = append(diags, diag.Diagnostic{
+				Severity: diag.Error,
+				Summary:  "Unable to modify object storage bucket",
+				Detail:   fmt.Sprint(err),
+			})
+			return diags
+		}
+	}
+
+	return resourceObjectStorageBucketRead(ctx, d, m)
+}
+
+func resourceObjectStorageBucketDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	cfg := m.(*Config)
+
+	err := cfg.apiRequest(ctx, http.MethodDelete, "storage/bucket", url.Values{"name": {d.Id()}}, nil)
+	if err != nil && !isNotFoundError(err) {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Unable to delete object storage bucket",
+			Detail:   fmt.Sprint(err),
+		})
+		return diags
+	}
+	d.SetId("")
+	return diags
+}
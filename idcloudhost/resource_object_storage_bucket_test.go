@@ -0,0 +1,107 @@
+package idcloudhost
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestAccIdcloudhostObjectStorageBucket_basic(t *testing.T) {
+	api := newFakeAPI(t)
+	resourceName := "idcloudhost_object_storage_bucket.test"
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: testAccProviderFactories,
+		CheckDestroy:      testAccCheckObjectStorageBucketDestroy(api),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccObjectStorageBucketConfig(api, "assets-bucket", ""),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckObjectStorageBucketExists(api, resourceName),
+					resource.TestCheckResourceAttr(resourceName, "name", "assets-bucket"),
+					resource.TestCheckResourceAttr(resourceName, "acl", "private"),
+					resource.TestCheckResourceAttr(resourceName, "versioning", "false"),
+					resource.TestCheckResourceAttr(resourceName, "billing_account_id", strconv.Itoa(fakeAPIBillingAccountID)),
+				),
+			},
+			{
+				Config: testAccObjectStorageBucketConfig(api, "assets-bucket", `
+  acl                = "public-read"
+  versioning         = true
+  billing_account_id = 1200132375
+`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(resourceName, "acl", "public-read"),
+					resource.TestCheckResourceAttr(resourceName, "versioning", "true"),
+					resource.TestCheckResourceAttr(resourceName, "billing_account_id", "1200132375"),
+				),
+			},
+			{
+				ResourceName:      resourceName,
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func TestAccIdcloudhostObjectStorageBucket_invalidName(t *testing.T) {
+	api := newFakeAPI(t)
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: testAccProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config:      testAccObjectStorageBucketConfig(api, "Assets_Bucket", ""),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile(`"name" must be 3 to 63 lowercase letters`),
+			},
+			{
+				Config: testAccObjectStorageBucketConfig(api, "assets-bucket", `
+  acl = "public-write"
+`),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile(`"acl" must be either "private" or "public-read"`),
+			},
+		},
+	})
+}
+
+func testAccCheckObjectStorageBucketExists(api *fakeAPI, resourceName string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[resourceName]
+		if !ok {
+			return fmt.Errorf("not found: %s", resourceName)
+		}
+		if api.bucket(rs.Primary.ID) == nil {
+			return fmt.Errorf("object storage bucket %s does not exist in the API", rs.Primary.ID)
+		}
+		return nil
+	}
+}
+
+func testAccCheckObjectStorageBucketDestroy(api *fakeAPI) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		for _, rs := range s.RootModule().Resources {
+			if rs.Type != "idcloudhost_object_storage_bucket" {
+				continue
+			}
+			if api.bucket(rs.Primary.ID) != nil {
+				return fmt.Errorf("object storage bucket %s still exists", rs.Primary.ID)
+			}
+		}
+		return nil
+	}
+}
+
+func testAccObjectStorageBucketConfig(api *fakeAPI, name string, extra string) string {
+	return api.providerConfig() + fmt.Sprintf(`
+resource "idcloudhost_object_storage_bucket" "test" {
+  name = %q
+%s}
+`, name, extra)
+}
@@ -0,0 +1,108 @@
+package idcloudhost
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// objectStorageKey is an S3 access key of the object storage user of the
+// account. It grants access to every bucket of the account.
+type objectStorageKey struct {
+	AccessKey string `json:"access_key"`
+	SecretKey string `json:"secret_key"`
+}
+
+func resourceObjectStorageKey() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceObjectStorageKeyCreate,
+		ReadContext:   resourceObjectStorageKeyRead,
+		DeleteContext: resourceObjectStorageKeyDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
+		Schema: map[string]*schema.Schema{
+			"access_key": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"secret_key": {
+				Type:      schema.TypeString,
+				Computed:  true,
+				Sensitive: true,
+			},
+		},
+	}
+}
+
+func resourceObjectStorageKeyCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	cfg := m.(*Config)
+
+	var key objectStorageKey
+	if err := cfg.apiRequest(ctx, http.MethodPost, "storage/user/keys", url.Values{}, &key); err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Unable to create object storage key",
+			Detail:   fmt.Sprint(err),
+		})
+		return diags
+	}
+	d.SetId(key.AccessKey)
+	// the secret is only returned when the key is created
+	if err := d.Set("secret_key", key.SecretKey); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return resourceObjectStorageKeyRead(ctx, d, m)
+}
+
+func resourceObjectStorageKeyRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	cfg := m.(*Config)
+
+	var keys []objectStorageKey
+	if err := cfg.apiRequest(ctx, http.MethodGet, "storage/user/keys", nil, &keys); err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Unable to list object storage keys",
+			Detail:   fmt.Sprint(err),
+		})
+		return diags
+	}
+	for _, key := range keys {
+		if key.AccessKey != d.Id() {
+			continue
+		}
+		if err := d.Set("access_key", key.AccessKey); err != nil {
+			return diag.FromErr(err)
+		}
+		return diags
+	}
+
+	log.Printf("[WARN] Object storage key %s not found, removing from state", d.Id())
+	d.SetId("")
+	return diags
+}
+
+func resourceObjectStorageKeyDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	cfg := m.(*Config)
+
+	err := cfg.apiRequest(ctx, http.MethodDelete, "storage/user/keys", url.Values{"access_key": {d.Id()}}, nil)
+	if err != nil && !isNotFoundError(err) {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Unable to delete object storage key",
+			Detail:   fmt.Sprint(err),
+		})
+		return diags
+	}
+	d.SetId("")
+	return diags
+}
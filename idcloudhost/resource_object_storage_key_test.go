@@ -0,0 +1,73 @@
+package idcloudhost
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestAccIdcloudhostObjectStorageKey_basic(t *testing.T) {
+	api := newFakeAPI(t)
+	resourceName := "idcloudhost_object_storage_key.test"
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: testAccProviderFactories,
+		CheckDestroy:      testAccCheckObjectStorageKeyDestroy(api),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccObjectStorageKeyConfig(api),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckObjectStorageKeyExists(api, resourceName),
+					resource.TestCheckResourceAttrPair(resourceName, "access_key", resourceName, "id"),
+					resource.TestCheckResourceAttrSet(resourceName, "secret_key"),
+				),
+			},
+			// the secret is only known when the key is created
+			{
+				ResourceName:            resourceName,
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"secret_key"},
+			},
+		},
+	})
+}
+
+func testAccCheckObjectStorageKeyExists(api *fakeAPI, resourceName string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[resourceName]
+		if !ok {
+			return fmt.Errorf("not found: %s", resourceName)
+		}
+		key := api.objectStorageKey(rs.Primary.ID)
+		if key == nil {
+			return fmt.Errorf("object storage key %s does not exist in the API", rs.Primary.ID)
+		}
+		if rs.Primary.Attributes["secret_key"] != key.SecretKey {
+			return fmt.Errorf("secret_key of object storage key %s does not match the API", rs.Primary.ID)
+		}
+		return nil
+	}
+}
+
+func testAccCheckObjectStorageKeyDestroy(api *fakeAPI) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		for _, rs := range s.RootModule().Resources {
+			if rs.Type != "idcloudhost_object_storage_key" {
+				continue
+			}
+			if api.objectStorageKey(rs.Primary.ID) != nil {
+				return fmt.Errorf("object storage key %s still exists", rs.Primary.ID)
+			}
+		}
+		return nil
+	}
+}
+
+func testAccObjectStorageKeyConfig(api *fakeAPI) string {
+	return api.providerConfig() + `
+resource "idcloudhost_object_storage_key" "test" {}
+`
+}
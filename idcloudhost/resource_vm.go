@@ -3,9 +3,10 @@ package idcloudhost
 import (
 	"context"
 	"fmt"
+	"log"
+	"strings"
 	"time"
 
-	idcloudhostAPI "github.com/bapung/idcloudhost-go-client-library/idcloudhost/api"
 	idcloudhostVM "github.com/bapung/idcloudhost-go-client-library/idcloudhost/vm"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
@@ -19,11 +20,18 @@ func resourceVirtualMachine() *schema.Resource {
 		DeleteContext: resourceVirtualMachineDelete,
 		Timeouts: &schema.ResourceTimeout{
 			Create: schema.DefaultTimeout(5 * time.Minute),
+			Update: schema.DefaultTimeout(10 * time.Minute),
 		},
 		Importer: &schema.ResourceImporter{
-			StateContext: schema.ImportStatePassthroughContext,
+			StateContext: resourceVirtualMachineImport,
 		},
+		CustomizeDiff: resourceVirtualMachineCustomizeDiff,
 		Schema: map[string]*schema.Schema{
+			"allow_stopping_for_update": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
 			"backup": {
 				Type:     schema.TypeBool,
 				Optional: true,
@@ -31,7 +39,8 @@ func resourceVirtualMachine() *schema.Resource {
 			},
 			"billing_account_id": {
 				Type:     schema.TypeInt,
-				Required: true,
+				Optional: true,
+				Computed: true,
 			},
 			"created_at": {
 				Type:     schema.TypeString,
@@ -83,18 +92,42 @@ func resourceVirtualMachine() *schema.Resource {
 				Type:     schema.TypeString,
 				Required: true,
 			},
-			"os_name": {
+			"network_uuid": {
 				Type:     schema.TypeString,
-				Required: true,
+				Optional: true,
+				Computed: true,
+				ForceNew: true,
+			},
+			"os_name": {
+				Type:             schema.TypeString,
+				Required:         true,
+				ForceNew:         true,
+				DiffSuppressFunc: suppressCaseDiff,
 			},
 			"os_version": {
-				Type:     schema.TypeString,
-				Required: true,
+				Type:             schema.TypeString,
+				Required:         true,
+				ForceNew:         true,
+				DiffSuppressFunc: suppressCaseDiff,
 			},
 			"initial_password": {
-				Type:      schema.TypeString,
-				Required:  true,
-				Sensitive: true,
+				Type:             schema.TypeString,
+				Required:         true,
+				ForceNew:         true,
+				Sensitive:        true,
+				DiffSuppressFunc: suppressCreateOnlyDiff,
+			},
+			"power_state": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+				ValidateFunc: func(val interface{}, key string) (warns []string, errs []error) {
+					v := val.(string)
+					if v != vmStatusRunning && v != vmStatusStopped {
+						errs = append(errs, fmt.Errorf("%q must be either %q or %q, got: %s", key, vmStatusRunning, vmStatusStopped, v))
+					}
+					return
+				},
 			},
 			"private_ipv4": {
 				Type:     schema.TypeString,
@@ -103,14 +136,23 @@ func resourceVirtualMachine() *schema.Resource {
 			"public_key": {
 				Type:     schema.TypeString,
 				Optional: true,
+				ForceNew: true,
+			},
+			"region": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+				ForceNew: true,
 			},
 			"source_replica": {
 				Type:     schema.TypeString,
 				Optional: true,
+				ForceNew: true,
 			},
 			"source_uuid": {
 				Type:     schema.TypeString,
 				Optional: true,
+				ForceNew: true,
 			},
 			"status": {
 				Type:     schema.TypeString,
@@ -191,8 +233,26 @@ func resourceVirtualMachine() *schema.Resource {
 				Computed: true,
 			},
 			"username": {
-				Type:     schema.TypeString,
-				Required: true,
+				Type:             schema.TypeString,
+				Required:         true,
+				ForceNew:         true,
+				DiffSuppressFunc: suppressCaseDiff,
+			},
+			"user_data": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				ForceNew:      true,
+				ConflictsWith: []string{"user_data_base64"},
+				StateFunc:     userDataStateFunc,
+				ValidateFunc:  validateUserData,
+			},
+			"user_data_base64": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				ForceNew:      true,
+				ConflictsWith: []string{"user_data"},
+				StateFunc:     userDataBase64StateFunc,
+				ValidateFunc:  validateUserDataBase64,
 			},
 			"uuid": {
 				Type:     schema.TypeString,
@@ -213,13 +273,123 @@ func resourceVirtualMachine() *schema.Resource {
 	}
 }
 
+// vmFixedAttributes can only be set when a VM is created, but replacing the VM
+// for them would be out of proportion, so changing them is rejected at plan time.
+var vmFixedAttributes = []string{"billing_account_id", "description"}
+
+func resourceVirtualMachineCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	if err := validateVMOSImage(ctx, d, m.(*Config)); err != nil {
+		return err
+	}
+	if err := validateVMSourceReplica(ctx, d, m.(*Config)); err != nil {
+		return err
+	}
+	if d.Id() == "" {
+		return nil
+	}
+	for _, key := range vmFixedAttributes {
+		if d.HasChange(key) {
+			oldValue, newValue := d.GetChange(key)
+			return fmt.Errorf("%q cannot be changed on an existing VM (from %v to %v), revert it or replace the VM explicitly", key, oldValue, newValue)
+		}
+	}
+	if d.HasChange("disks") {
+		oldSize, newSize := d.GetChange("disks")
+		if newSize.(int) < oldSize.(int) {
+			return fmt.Errorf("boot disk cannot be shrunk from %d to %d GB, shrinking disk is not possible", oldSize.(int), newSize.(int))
+		}
+	}
+	return nil
+}
+
+// validateVMOSImage checks os_name and os_version of a new VM against the image
+// catalog of the region, so a typo fails the plan instead of the create.
+func validateVMOSImage(ctx context.Context, d *schema.ResourceDiff, cfg *Config) error {
+	if d.Id() != "" && !d.HasChanges("os_name", "os_version") {
+		return nil
+	}
+	if !d.NewValueKnown("os_name") || !d.NewValueKnown("os_version") || !regionKnown(d) {
+		return nil
+	}
+	osName := d.Get("os_name").(string)
+	osVersion := d.Get("os_version").(string)
+	region := cfg.resourceRegion(d)
+
+	images, err := cfg.osImages(ctx, region)
+	if err != nil {
+		log.Printf("[WARN] Unable to list OS images, skipping validation of %s %s: %s", osName, osVersion, err)
+		return nil
+	}
+	var versions []string
+	for _, image := range images {
+		if !strings.EqualFold(image.OSName, osName) {
+			continue
+		}
+		if strings.EqualFold(image.OSVersion, osVersion) {
+			return nil
+		}
+		versions = append(versions, image.OSVersion)
+	}
+	if len(versions) == 0 {
+		var osNames []string
+		for _, image := range images {
+			if len(osNames) == 0 || osNames[len(osNames)-1] != image.OSName {
+				osNames = append(osNames, image.OSName)
+			}
+		}
+		return fmt.Errorf("os_name %q is not available in region %s, available: %s", osName, region, strings.Join(osNames, ", "))
+	}
+	return fmt.Errorf("os_version %q of %q is not available in region %s, available: %s", osVersion, osName, region, strings.Join(versions, ", "))
+}
+
+// validateVMSourceReplica checks that a VM cloned from a snapshot exists and
+// that its boot disk is large enough to hold the snapshot.
+func validateVMSourceReplica(ctx context.Context, d *schema.ResourceDiff, cfg *Config) error {
+	if d.Id() != "" && !d.HasChange("source_replica") {
+		return nil
+	}
+	if !d.NewValueKnown("source_replica") || !d.NewValueKnown("disks") || !regionKnown(d) {
+		return nil
+	}
+	snapshotUUID := d.Get("source_replica").(string)
+	if snapshotUUID == "" {
+		return nil
+	}
+
+	snapshot, err := getVMSnapshot(ctx, cfg, cfg.resourceRegion(d), snapshotUUID)
+	if isNotFoundError(err) {
+		return fmt.Errorf("source_replica %s does not exist in region %s", snapshotUUID, cfg.resourceRegion(d))
+	}
+	if err != nil {
+		log.Printf("[WARN] Unable to get snapshot %s, skipping validation of source_replica: %s", snapshotUUID, err)
+		return nil
+	}
+	if disks := d.Get("disks").(int); disks < snapshot.SizeGB {
+		return fmt.Errorf("disks must be at least the %d GB of snapshot %s, got: %d", snapshot.SizeGB, snapshotUUID, disks)
+	}
+	return nil
+}
+
 func resourceVirtualMachineCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	c := m.(*idcloudhostAPI.APIClient)
+	cfg := m.(*Config)
+	region := cfg.resourceRegion(d)
+	c, err := cfg.Client(region)
+	if err != nil {
+		return diag.FromErr(err)
+	}
 	var diags diag.Diagnostics
 
+	billingAccountId, err := cfg.billingAccountID(ctx, d)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	userData, err := vmUserData(d)
+	if err != nil {
+		return diag.FromErr(err)
+	}
 	newVM := &idcloudhostVM.NewVM{
 		Backup:          d.Get("backup").(bool),
-		BillingAccount:  d.Get("billing_account_id").(int), //should be automatically assigned to "default" billing account if not specified
+		BillingAccount:  billingAccountId,
 		Description:     d.Get("description").(string),
 		Disks:           d.Get("disks").(int),
 		Name:            d.Get("name").(string),
@@ -233,14 +403,16 @@ func resourceVirtualMachineCreate(ctx context.Context, d *schema.ResourceData, m
 		VCPU:            d.Get("vcpu").(int),
 		Memory:          d.Get("memory").(int),
 		ReservePublicIP: false,
+		UserData:        userData,
+		NetworkUUID:     d.Get("network_uuid").(string),
 	}
 
 	vmApi := c.VM
-	if err := vmApi.Create(*newVM); err != nil {
+	if err = vmApi.Create(*newVM); err != nil {
 		diags = append(diags, diag.Diagnostic{
 			Severity: diag.Error,
 			Summary:  "Unable to create new VM",
-			Detail:   "",
+			Detail:   fmt.Sprint(err),
 		})
 
 		return diags
@@ -248,17 +420,65 @@ func resourceVirtualMachineCreate(ctx context.Context, d *schema.ResourceData, m
 
 	d.SetId(vmApi.VM.UUID)
 
+	if _, err := waitForVMReady(ctx, cfg, region, d.Id(), d.Timeout(schema.TimeoutCreate)); err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "VM did not become ready",
+			Detail:   fmt.Sprint(err),
+		})
+		return diags
+	}
+
+	if d.Get("power_state").(string) == vmStatusStopped {
+		err = setVMPowerState(ctx, cfg, region, d.Id(), vmStatusStopped, d.Timeout(schema.TimeoutCreate))
+		if err != nil {
+			diags = append(diags, diag.Diagnostic{
+				Severity: diag.Error,
+				Summary:  "Unable to stop new VM",
+				Detail:   fmt.Sprint(err),
+			})
+			return diags
+		}
+	}
+
 	resourceVirtualMachineRead(ctx, d, m)
 
 	return diags
 }
 
+func resourceVirtualMachineImport(ctx context.Context, d *schema.ResourceData, m interface{}) ([]*schema.ResourceData, error) {
+	// set the default, so it does not show up as a change after the import
+	if err := d.Set("allow_stopping_for_update", false); err != nil {
+		return nil, err
+	}
+	imported, err := importStateWithRegion(1)(ctx, d, m)
+	if err != nil {
+		return nil, err
+	}
+	if err := refreshVMNetworkUUID(ctx, d, m.(*Config), d.Id()); err != nil {
+		log.Printf("[WARN] Unable to get network of VM %s: %s", d.Id(), err)
+	}
+	return imported, nil
+}
+
+func refreshVMNetworkUUID(ctx context.Context, d *schema.ResourceData, cfg *Config, uuid string) error {
+	networkUUID, err := vmNetworkUUID(ctx, cfg, cfg.resourceRegion(d), uuid)
+	if err != nil {
+		return err
+	}
+	return d.Set("network_uuid", networkUUID)
+}
+
 func resourceVirtualMachineRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	c := m.(*idcloudhostAPI.APIClient)
+	cfg := m.(*Config)
 	var diags diag.Diagnostics
 	uuid := d.Id()
-	vmApi := c.VM
-	err := vmApi.Get(uuid)
+	vm, err := getVM(ctx, cfg, cfg.resourceRegion(d), uuid)
+	if isNotFoundError(err) {
+		log.Printf("[WARN] VM %s not found, removing from state", uuid)
+		d.SetId("")
+		return diags
+	}
 	if err != nil {
 		diags = append(diags, diag.Diagnostic{
 			Severity: diag.Error,
@@ -268,7 +488,7 @@ func resourceVirtualMachineRead(ctx context.Context, d *schema.ResourceData, m i
 		return diags
 	}
 
-	err = setVmResource(d, &vmApi.VM)
+	err = setVmResource(d, vm)
 	if err != nil {
 		diags = append(diags, diag.Diagnostic{
 			Severity: diag.Error,
@@ -277,6 +497,17 @@ func resourceVirtualMachineRead(ctx context.Context, d *schema.ResourceData, m i
 		})
 		return diags
 	}
+	if err := d.Set("region", cfg.resourceRegion(d)); err != nil {
+		return diag.FromErr(err)
+	}
+
+	// finding the network lists every network of the region, so it is only
+	// refreshed for VMs that have one in their configuration or state
+	if previousUUID := d.Get("network_uuid").(string); previousUUID != "" {
+		if err := refreshVMNetworkUUID(ctx, d, cfg, uuid); err != nil {
+			log.Printf("[WARN] Unable to get network of VM %s, keeping %s: %s", uuid, previousUUID, err)
+		}
+	}
 
 	return diags
 }
@@ -284,9 +515,29 @@ func resourceVirtualMachineRead(ctx context.Context, d *schema.ResourceData, m i
 func resourceVirtualMachineUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	var diags diag.Diagnostics
 	var isSomethingChanged = true
-	c := m.(*idcloudhostAPI.APIClient)
+	cfg := m.(*Config)
+	region := cfg.resourceRegion(d)
+	c, err := cfg.Client(region)
+	if err != nil {
+		return diag.FromErr(err)
+	}
 	vmApi := c.VM
 	uuid := d.Id()
+	powerState := d.Get("power_state").(string)
+
+	// stop before applying changes, so they can include vcpu and memory
+	if d.HasChange("power_state") && powerState == vmStatusStopped {
+		isSomethingChanged = true
+		err = setVMPowerState(ctx, cfg, region, uuid, vmStatusStopped, d.Timeout(schema.TimeoutUpdate))
+		if err != nil {
+			diags = append(diags, diag.Diagnostic{
+				Severity: diag.Error,
+				Summary:  "Unable to modify VM",
+				Detail:   fmt.Sprint(err),
+			})
+			return diags
+		}
+	}
 
 	if d.HasChanges("name", "vcpu", "memory") {
 		isSomethingChanged = true
@@ -305,37 +556,109 @@ func resourceVirtualMachineUpdate(ctx context.Context, d *schema.ResourceData, m
 			})
 			return diags
 		}
-		if d.HasChanges("vcpu", "memory") && vmApi.VM.Status != "stopped" {
+		stoppedForUpdate := false
+		if d.HasChanges("vcpu", "memory") && vmApi.VM.Status != vmStatusStopped {
+			if !d.Get("allow_stopping_for_update").(bool) {
+				diags = append(diags, diag.Diagnostic{
+					Severity: diag.Error,
+					Summary:  "Unable to modify VM",
+					Detail:   "Updating vcpu and ram requires VM to be stopped, set allow_stopping_for_update = true to let the provider stop and start it",
+				})
+				return diags
+			}
+			err = setVMPowerState(ctx, cfg, region, uuid, vmStatusStopped, d.Timeout(schema.TimeoutUpdate))
+			if err != nil {
+				diags = append(diags, diag.Diagnostic{
+					Severity: diag.Error,
+					Summary:  "Unable to modify VM",
+					Detail:   fmt.Sprint(err),
+				})
+				return diags
+			}
+			stoppedForUpdate = true
+		}
+		err = vmApi.Modify(*updatedVM)
+		if err != nil {
 			diags = append(diags, diag.Diagnostic{
 				Severity: diag.Error,
 				Summary:  "Unable to modify VM",
-				Detail:   "Updating vcpu and ram requires VM to be stopped",
+				Detail:   fmt.Sprint(err),
 			})
+			if stoppedForUpdate {
+				// do not leave the VM down because of a failed resize
+				if err := setVMPowerState(ctx, cfg, region, uuid, vmStatusRunning, d.Timeout(schema.TimeoutUpdate)); err != nil {
+					diags = append(diags, diag.Diagnostic{
+						Severity: diag.Error,
+						Summary:  "Unable to start VM after failed update",
+						Detail:   fmt.Sprint(err),
+					})
+				}
+			}
 			return diags
 		}
-		err = vmApi.Modify(*updatedVM)
+		if stoppedForUpdate && powerState != vmStatusStopped {
+			err = setVMPowerState(ctx, cfg, region, uuid, vmStatusRunning, d.Timeout(schema.TimeoutUpdate))
+			if err != nil {
+				diags = append(diags, diag.Diagnostic{
+					Severity: diag.Error,
+					Summary:  "Unable to start VM after update",
+					Detail:   fmt.Sprint(err),
+				})
+				return diags
+			}
+		}
+		err = setVmResource(d, &vmApi.VM)
+		if err != nil {
+			return diags
+		}
+	}
+
+	if d.HasChange("disks") {
+		isSomethingChanged = true
+		err = vmApi.Get(uuid)
 		if err != nil {
 			diags = append(diags, diag.Diagnostic{
 				Severity: diag.Error,
-				Summary:  "Unable to modify VM",
-				Detail:   fmt.Sprint(err),
+				Summary:  "Unable to resize boot disk",
+				Detail:   "cannot fetch VM state for update, cannot update resource",
 			})
 			return diags
 		}
-		err = setVmResource(d, &vmApi.VM)
+		var primaryDiskUUID string
+		for _, disk := range vmApi.VM.Storage {
+			if disk.Primary {
+				primaryDiskUUID = disk.UUID
+			}
+		}
+		if primaryDiskUUID == "" {
+			diags = append(diags, diag.Diagnostic{
+				Severity: diag.Error,
+				Summary:  "Unable to resize boot disk",
+				Detail:   fmt.Sprintf("VM %s has no primary disk", uuid),
+			})
+			return diags
+		}
+		diskApi := c.Disk
+		diskApi.Bind(uuid)
+		err = diskApi.Modify(primaryDiskUUID, d.Get("disks").(int))
 		if err != nil {
+			diags = append(diags, diag.Diagnostic{
+				Severity: diag.Error,
+				Summary:  "Unable to resize boot disk",
+				Detail:   fmt.Sprint(err),
+			})
 			return diags
 		}
 	}
 
 	if d.HasChange("backup") {
 		isSomethingChanged = true
-		err := vmApi.ToggleAutoBackup(uuid)
+		err := setVMBackup(vmApi, uuid, d.Get("backup").(bool))
 		if err != nil {
 			diags = append(diags, diag.Diagnostic{
 				Severity: diag.Error,
 				Summary:  "Unable to modify VM",
-				Detail:   "Unable to toggle auto backup",
+				Detail:   fmt.Sprint(err),
 			})
 			return diags
 		}
@@ -349,6 +672,19 @@ func resourceVirtualMachineUpdate(ctx context.Context, d *schema.ResourceData, m
 			return diags
 		}
 	}
+	if d.HasChange("power_state") && powerState == vmStatusRunning {
+		isSomethingChanged = true
+		err = setVMPowerState(ctx, cfg, region, uuid, vmStatusRunning, d.Timeout(schema.TimeoutUpdate))
+		if err != nil {
+			diags = append(diags, diag.Diagnostic{
+				Severity: diag.Error,
+				Summary:  "Unable to modify VM",
+				Detail:   fmt.Sprint(err),
+			})
+			return diags
+		}
+	}
+
 	if isSomethingChanged {
 		d.Set("last_updated", time.Now().Format(time.RFC850))
 	}
@@ -358,10 +694,14 @@ func resourceVirtualMachineUpdate(ctx context.Context, d *schema.ResourceData, m
 
 func resourceVirtualMachineDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	var diags diag.Diagnostics
-	c := m.(*idcloudhostAPI.APIClient)
+	cfg := m.(*Config)
+	c, err := cfg.Client(cfg.resourceRegion(d))
+	if err != nil {
+		return diag.FromErr(err)
+	}
 	uuid := d.Id()
 	vmApi := c.VM
-	err := vmApi.Delete(uuid)
+	err = vmApi.Delete(uuid)
 	if err != nil {
 		return diag.FromErr(err)
 	}
@@ -0,0 +1,127 @@
+package idcloudhost
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// resourceVMBackupRestore restores a VM from one of its backups when it is
+// created. A restore cannot be undone, so deleting the resource only removes
+// it from the state.
+func resourceVMBackupRestore() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceVMBackupRestoreCreate,
+		ReadContext:   resourceVMBackupRestoreRead,
+		DeleteContext: resourceVMBackupRestoreDelete,
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(20 * time.Minute),
+		},
+		Schema: map[string]*schema.Schema{
+			"backup_uuid": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"region": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+				ForceNew: true,
+			},
+			"restored_at": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"triggers": {
+				Type:     schema.TypeMap,
+				Optional: true,
+				ForceNew: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"vm_uuid": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+		},
+	}
+}
+
+func resourceVMBackupRestoreCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	cfg := m.(*Config)
+	region := cfg.resourceRegion(d)
+	vmUUID := d.Get("vm_uuid").(string)
+	backupUUID := d.Get("backup_uuid").(string)
+
+	log.Printf("[INFO] Restoring VM %s from backup %s", vmUUID, backupUUID)
+	form := url.Values{
+		"uuid":        {vmUUID},
+		"backup_uuid": {backupUUID},
+	}
+	if err := cfg.apiRequest(ctx, http.MethodPost, fmt.Sprintf("%s/user-resource/vm/backup/restore", region), form, nil); err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Unable to restore VM from backup",
+			Detail:   fmt.Sprint(err),
+		})
+		return diags
+	}
+	d.SetId(fmt.Sprintf("%s/%s", vmUUID, backupUUID))
+	if err := d.Set("restored_at", time.Now().Format(time.RFC850)); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if _, err := waitForVMReady(ctx, cfg, region, vmUUID, d.Timeout(schema.TimeoutCreate)); err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "VM did not come back after restoring backup",
+			Detail:   fmt.Sprint(err),
+		})
+		return diags
+	}
+
+	return resourceVMBackupRestoreRead(ctx, d, m)
+}
+
+func resourceVMBackupRestoreRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	cfg := m.(*Config)
+	region := cfg.resourceRegion(d)
+
+	vmUUID := d.Get("vm_uuid").(string)
+	_, err := getVM(ctx, cfg, region, vmUUID)
+	if isNotFoundError(err) {
+		log.Printf("[WARN] VM %s not found, removing backup restore from state", vmUUID)
+		d.SetId("")
+		return diags
+	}
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Unable to get VM",
+			Detail:   fmt.Sprint(err),
+		})
+		return diags
+	}
+
+	if err := d.Set("region", region); err != nil {
+		return diag.FromErr(err)
+	}
+	return diags
+}
+
+func resourceVMBackupRestoreDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	log.Printf("[DEBUG] Removing backup restore %s from state, the VM is left as is", d.Id())
+	d.SetId("")
+	return nil
+}
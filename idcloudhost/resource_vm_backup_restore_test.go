@@ -0,0 +1,98 @@
+package idcloudhost
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestAccIdcloudhostVMBackupRestore_basic(t *testing.T) {
+	api := newFakeAPI(t)
+	vm := api.addVM("web")
+	api.addBackup(vm.UUID, "2022-11-01 01:00:00")
+	latest := api.addBackup(vm.UUID, "2022-11-02 01:00:00")
+	resourceName := "idcloudhost_vm_backup_restore.test"
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: testAccProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccVMBackupRestoreConfig(api, vm.UUID, "1"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(resourceName, "id", fmt.Sprintf("%s/%s", vm.UUID, latest.UUID)),
+					resource.TestCheckResourceAttr(resourceName, "backup_uuid", latest.UUID),
+					resource.TestCheckResourceAttrSet(resourceName, "restored_at"),
+					testAccCheckVMRestoredFrom(api, vm.UUID, latest.UUID),
+				),
+			},
+			// changing triggers restores again
+			{
+				Config: testAccVMBackupRestoreConfig(api, vm.UUID, "2"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(resourceName, "triggers.run", "2"),
+					testAccCheckVMRestoredFrom(api, vm.UUID, latest.UUID),
+				),
+			},
+			// removing the restore leaves the VM alone
+			{
+				Config: api.providerConfig(),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckVMRestoredFrom(api, vm.UUID, latest.UUID),
+				),
+			},
+		},
+	})
+}
+
+func TestAccIdcloudhostVMBackupRestore_otherVM(t *testing.T) {
+	api := newFakeAPI(t)
+	vm := api.addVM("web")
+	backup := api.addBackup(api.addVM("db").UUID, "2022-11-01 01:00:00")
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: testAccProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: api.providerConfig() + fmt.Sprintf(`
+resource "idcloudhost_vm_backup_restore" "test" {
+  vm_uuid     = %q
+  backup_uuid = %q
+}
+`, vm.UUID, backup.UUID),
+				ExpectError: regexp.MustCompile("Unable to restore VM from backup"),
+			},
+		},
+	})
+}
+
+func testAccCheckVMRestoredFrom(api *fakeAPI, vmUUID string, backupUUID string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		if got := api.restoredBackup(vmUUID); got != backupUUID {
+			return fmt.Errorf("VM %s was restored from backup %q, expected %s", vmUUID, got, backupUUID)
+		}
+		if status := api.vm(vmUUID).Status; status != vmStatusRunning {
+			return fmt.Errorf("VM %s is %s after the restore", vmUUID, status)
+		}
+		return nil
+	}
+}
+
+func testAccVMBackupRestoreConfig(api *fakeAPI, vmUUID string, trigger string) string {
+	return api.providerConfig() + fmt.Sprintf(`
+data "idcloudhost_vm_backups" "test" {
+  vm_uuid = %q
+}
+
+resource "idcloudhost_vm_backup_restore" "test" {
+  vm_uuid     = %q
+  backup_uuid = data.idcloudhost_vm_backups.test.backups[0].uuid
+
+  triggers = {
+    run = %q
+  }
+}
+`, vmUUID, vmUUID, trigger)
+}
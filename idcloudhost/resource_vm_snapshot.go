@@ -0,0 +1,229 @@
+package idcloudhost
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+const (
+	snapshotStatusAvailable = "available"
+	snapshotStatusCreating  = "creating"
+	snapshotStatusDeleting  = "deleting"
+	snapshotStatusError     = "error"
+
+	// snapshotStateDeleted is reported by snapshotStateRefreshFunc once the
+	// snapshot is gone, the API has no such status.
+	snapshotStateDeleted = "deleted"
+)
+
+// vmSnapshot is a point-in-time copy of the boot disk of a VM. New VMs can be
+// created from it by passing its UUID as source_replica.
+type vmSnapshot struct {
+	UUID      string `json:"uuid"`
+	Name      string `json:"name"`
+	VMUUID    string `json:"vm_uuid"`
+	SizeGB    int    `json:"size_gb"`
+	Status    string `json:"status"`
+	CreatedAt string `json:"created_at"`
+}
+
+func resourceVMSnapshot() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceVMSnapshotCreate,
+		ReadContext:   resourceVMSnapshotRead,
+		DeleteContext: resourceVMSnapshotDelete,
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(10 * time.Minute),
+			Delete: schema.DefaultTimeout(10 * time.Minute),
+		},
+		Importer: &schema.ResourceImporter{
+			StateContext: importStateWithRegion(1),
+		},
+		Schema: map[string]*schema.Schema{
+			"created_at": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"name": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"region": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+				ForceNew: true,
+			},
+			"size": {
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
+			"status": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"uuid": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"vm_uuid": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+		},
+	}
+}
+
+func resourceVMSnapshotCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	cfg := m.(*Config)
+	region := cfg.resourceRegion(d)
+
+	form := url.Values{
+		"uuid": {d.Get("vm_uuid").(string)},
+		"name": {d.Get("name").(string)},
+	}
+	var snapshot vmSnapshot
+	if err := cfg.apiRequest(ctx, http.MethodPost, fmt.Sprintf("%s/user-resource/vm/snapshot", region), form, &snapshot); err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Unable to create VM snapshot",
+			Detail:   fmt.Sprint(err),
+		})
+		return diags
+	}
+	d.SetId(snapshot.UUID)
+
+	stateConf := &resource.StateChangeConf{
+		Pending:    []string{snapshotStatusCreating},
+		Target:     []string{snapshotStatusAvailable},
+		Refresh:    snapshotStateRefreshFunc(ctx, cfg, region, snapshot.UUID),
+		Timeout:    d.Timeout(schema.TimeoutCreate),
+		Delay:      vmPollDelay,
+		MinTimeout: vmPollMinTimeout,
+	}
+	if _, err := stateConf.WaitForStateContext(ctx); err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "VM snapshot did not become available",
+			Detail:   fmt.Sprintf("error waiting for snapshot %s of VM %s: %s", snapshot.UUID, d.Get("vm_uuid").(string), err),
+		})
+		return diags
+	}
+
+	return resourceVMSnapshotRead(ctx, d, m)
+}
+
+func resourceVMSnapshotRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	cfg := m.(*Config)
+	region := cfg.resourceRegion(d)
+
+	snapshot, err := getVMSnapshot(ctx, cfg, region, d.Id())
+	if isNotFoundError(err) {
+		log.Printf("[WARN] VM snapshot %s not found, removing from state", d.Id())
+		d.SetId("")
+		return diags
+	}
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Unable to get VM snapshot",
+			Detail:   fmt.Sprint(err),
+		})
+		return diags
+	}
+
+	values := map[string]interface{}{
+		"created_at": snapshot.CreatedAt,
+		"name":       snapshot.Name,
+		"region":     region,
+		"size":       snapshot.SizeGB,
+		"status":     snapshot.Status,
+		"uuid":       snapshot.UUID,
+		"vm_uuid":    snapshot.VMUUID,
+	}
+	for key, value := range values {
+		if err := d.Set(key, value); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+	return diags
+}
+
+func resourceVMSnapshotDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	cfg := m.(*Config)
+	region := cfg.resourceRegion(d)
+
+	path := fmt.Sprintf("%s/user-resource/vm/snapshot", region)
+	err := cfg.apiRequest(ctx, http.MethodDelete, path, url.Values{"uuid": {d.Id()}}, nil)
+	if isNotFoundError(err) {
+		d.SetId("")
+		return diags
+	}
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Unable to delete VM snapshot",
+			Detail:   fmt.Sprint(err),
+		})
+		return diags
+	}
+
+	stateConf := &resource.StateChangeConf{
+		Pending:    []string{snapshotStatusAvailable, snapshotStatusDeleting},
+		Target:     []string{snapshotStateDeleted},
+		Refresh:    snapshotStateRefreshFunc(ctx, cfg, region, d.Id()),
+		Timeout:    d.Timeout(schema.TimeoutDelete),
+		Delay:      vmPollDelay,
+		MinTimeout: vmPollMinTimeout,
+	}
+	if _, err := stateConf.WaitForStateContext(ctx); err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "VM snapshot was not deleted",
+			Detail:   fmt.Sprintf("error waiting for snapshot %s to be deleted: %s", d.Id(), err),
+		})
+		return diags
+	}
+	d.SetId("")
+	return diags
+}
+
+func getVMSnapshot(ctx context.Context, cfg *Config, region string, uuid string) (*vmSnapshot, error) {
+	var snapshot vmSnapshot
+	path := fmt.Sprintf("%s/user-resource/vm/snapshot", region)
+	if err := cfg.apiRequest(ctx, http.MethodGet, path, url.Values{"uuid": {uuid}}, &snapshot); err != nil {
+		return nil, err
+	}
+	return &snapshot, nil
+}
+
+// snapshotStateRefreshFunc polls a snapshot and reports its status as the
+// state, or snapshotStateDeleted once it does not exist anymore.
+func snapshotStateRefreshFunc(ctx context.Context, cfg *Config, region string, uuid string) resource.StateRefreshFunc {
+	return func() (interface{}, string, error) {
+		snapshot, err := getVMSnapshot(ctx, cfg, region, uuid)
+		if isNotFoundError(err) {
+			return struct{}{}, snapshotStateDeleted, nil
+		}
+		if err != nil {
+			return nil, "", err
+		}
+		if snapshot.Status == snapshotStatusError {
+			return snapshot, snapshot.Status, fmt.Errorf("snapshot %s ended in status %q", uuid, snapshot.Status)
+		}
+		return snapshot, snapshot.Status, nil
+	}
+}
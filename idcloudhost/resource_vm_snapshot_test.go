@@ -0,0 +1,118 @@
+package idcloudhost
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestAccIdcloudhostVMSnapshot_basic(t *testing.T) {
+	api := newFakeAPI(t)
+	resourceName := "idcloudhost_vm_snapshot.test"
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: testAccProviderFactories,
+		CheckDestroy:      testAccCheckVMSnapshotDestroy(api),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccVMSnapshotConfig(api, "pre-upgrade"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckVMSnapshotExists(api, resourceName),
+					resource.TestCheckResourceAttr(resourceName, "name", "pre-upgrade"),
+					resource.TestCheckResourceAttr(resourceName, "status", "available"),
+					resource.TestCheckResourceAttr(resourceName, "size", "20"),
+					resource.TestCheckResourceAttr(resourceName, "region", "jkt01"),
+					resource.TestCheckResourceAttrPair(resourceName, "vm_uuid", "idcloudhost_vm.test", "uuid"),
+				),
+			},
+			{
+				ResourceName:      resourceName,
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func TestAccIdcloudhostVMSnapshot_clone(t *testing.T) {
+	api := newFakeAPI(t)
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: testAccProviderFactories,
+		CheckDestroy:      testAccCheckVMSnapshotDestroy(api),
+		Steps: []resource.TestStep{
+			// The snapshot has to exist for source_replica to be validated
+			// when planning.
+			{
+				Config: testAccVMSnapshotConfig(api, "golden"),
+			},
+			{
+				Config:      testAccVMSnapshotConfigClone(api, 10),
+				ExpectError: regexp.MustCompile(`disks must be at least the 20 GB of snapshot`),
+			},
+			{
+				Config: testAccVMSnapshotConfigClone(api, 20),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrPair("idcloudhost_vm.clone", "source_replica", "idcloudhost_vm_snapshot.test", "uuid"),
+					resource.TestCheckResourceAttr("idcloudhost_vm.clone", "disks", "20"),
+				),
+			},
+		},
+	})
+}
+
+func testAccCheckVMSnapshotExists(api *fakeAPI, resourceName string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[resourceName]
+		if !ok {
+			return fmt.Errorf("not found: %s", resourceName)
+		}
+		if api.snapshot(rs.Primary.ID) == nil {
+			return fmt.Errorf("VM snapshot %s does not exist in the API", rs.Primary.ID)
+		}
+		return nil
+	}
+}
+
+func testAccCheckVMSnapshotDestroy(api *fakeAPI) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		for _, rs := range s.RootModule().Resources {
+			if rs.Type != "idcloudhost_vm_snapshot" {
+				continue
+			}
+			if api.snapshot(rs.Primary.ID) != nil {
+				return fmt.Errorf("VM snapshot %s still exists", rs.Primary.ID)
+			}
+		}
+		return nil
+	}
+}
+
+func testAccVMSnapshotConfig(api *fakeAPI, name string) string {
+	return testAccVMConfig(api, "testvm", 1, 1024, 20) + fmt.Sprintf(`
+resource "idcloudhost_vm_snapshot" "test" {
+  vm_uuid = idcloudhost_vm.test.uuid
+  name    = %q
+}
+`, name)
+}
+
+func testAccVMSnapshotConfigClone(api *fakeAPI, disks int) string {
+	return testAccVMSnapshotConfig(api, "golden") + fmt.Sprintf(`
+resource "idcloudhost_vm" "clone" {
+  name               = "clonevm"
+  os_name            = "ubuntu"
+  os_version         = "20.04"
+  disks              = %d
+  vcpu               = 1
+  memory             = 1024
+  username           = "example"
+  initial_password   = "Password123"
+  billing_account_id = %d
+  source_replica     = idcloudhost_vm_snapshot.test.uuid
+}
+`, disks, fakeAPIBillingAccountID)
+}
@@ -0,0 +1,767 @@
+package idcloudhost
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestAccIdcloudhostVM_basic(t *testing.T) {
+	api := newFakeAPI(t)
+	resourceName := "idcloudhost_vm.test"
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: testAccProviderFactories,
+		CheckDestroy:      testAccCheckVMDestroy(api),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccVMConfig(api, "testvm", 1, 1024, 20),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckVMExists(api, resourceName),
+					resource.TestCheckResourceAttr(resourceName, "name", "testvm"),
+					resource.TestCheckResourceAttr(resourceName, "vcpu", "1"),
+					resource.TestCheckResourceAttr(resourceName, "memory", "1024"),
+					resource.TestCheckResourceAttr(resourceName, "disks", "20"),
+					resource.TestCheckResourceAttr(resourceName, "status", "running"),
+					resource.TestCheckResourceAttr(resourceName, "power_state", "running"),
+					resource.TestCheckResourceAttr(resourceName, "storage.#", "1"),
+					resource.TestCheckResourceAttr(resourceName, "storage.0.primary", "true"),
+					resource.TestCheckResourceAttrSet(resourceName, "uuid"),
+					resource.TestCheckResourceAttrSet(resourceName, "private_ipv4"),
+				),
+			},
+			{
+				Config: testAccVMConfig(api, "testvm-renamed", 2, 2048, 30),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckVMExists(api, resourceName),
+					resource.TestCheckResourceAttr(resourceName, "name", "testvm-renamed"),
+					resource.TestCheckResourceAttr(resourceName, "vcpu", "2"),
+					resource.TestCheckResourceAttr(resourceName, "memory", "2048"),
+					resource.TestCheckResourceAttr(resourceName, "disks", "30"),
+					resource.TestCheckResourceAttr(resourceName, "storage.0.size", "30"),
+					resource.TestCheckResourceAttr(resourceName, "status", "running"),
+				),
+			},
+			{
+				ResourceName:      resourceName,
+				ImportState:       true,
+				ImportStateVerify: true,
+				ImportStateVerifyIgnore: []string{
+					"allow_stopping_for_update",
+					"initial_password",
+					"public_key",
+					"user_data",
+					"user_data_base64",
+				},
+			},
+		},
+	})
+}
+
+func TestAccIdcloudhostVM_powerState(t *testing.T) {
+	api := newFakeAPI(t)
+	resourceName := "idcloudhost_vm.test"
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: testAccProviderFactories,
+		CheckDestroy:      testAccCheckVMDestroy(api),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccVMConfigPowerState(api, "stopped"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckVMExists(api, resourceName),
+					resource.TestCheckResourceAttr(resourceName, "status", "stopped"),
+					resource.TestCheckResourceAttr(resourceName, "power_state", "stopped"),
+				),
+			},
+			{
+				Config: testAccVMConfigPowerState(api, "running"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(resourceName, "status", "running"),
+					resource.TestCheckResourceAttr(resourceName, "power_state", "running"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccIdcloudhostVM_disappears(t *testing.T) {
+	api := newFakeAPI(t)
+	resourceName := "idcloudhost_vm.test"
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: testAccProviderFactories,
+		CheckDestroy:      testAccCheckVMDestroy(api),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccVMConfig(api, "testvm", 1, 1024, 20),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckVMExists(api, resourceName),
+					testAccCheckVMDisappears(api, resourceName),
+				),
+				ExpectNonEmptyPlan: true,
+			},
+		},
+	})
+}
+
+func testAccCheckVMExists(api *fakeAPI, resourceName string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[resourceName]
+		if !ok {
+			return fmt.Errorf("not found: %s", resourceName)
+		}
+		if api.vm(rs.Primary.ID) == nil {
+			return fmt.Errorf("VM %s does not exist in the API", rs.Primary.ID)
+		}
+		return nil
+	}
+}
+
+func testAccCheckVMDisappears(api *fakeAPI, resourceName string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[resourceName]
+		if !ok {
+			return fmt.Errorf("not found: %s", resourceName)
+		}
+		api.deleteVM(rs.Primary.ID)
+		return nil
+	}
+}
+
+func testAccCheckVMDestroy(api *fakeAPI) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		for _, rs := range s.RootModule().Resources {
+			if rs.Type != "idcloudhost_vm" {
+				continue
+			}
+			if api.vm(rs.Primary.ID) != nil {
+				return fmt.Errorf("VM %s still exists", rs.Primary.ID)
+			}
+		}
+		return nil
+	}
+}
+
+func TestAccIdcloudhostVM_region(t *testing.T) {
+	api := newFakeAPI(t)
+	resourceName := "idcloudhost_vm.test"
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: testAccProviderFactories,
+		CheckDestroy:      testAccCheckVMDestroy(api),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccVMConfigRegion(api, "sgp01"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckVMExists(api, resourceName),
+					testAccCheckVMRegion(api, resourceName, "sgp01"),
+					resource.TestCheckResourceAttr(resourceName, "region", "sgp01"),
+				),
+			},
+			{
+				ResourceName:      resourceName,
+				ImportState:       true,
+				ImportStateIdFunc: testAccVMImportStateIdWithRegion(resourceName),
+				ImportStateVerify: true,
+				ImportStateVerifyIgnore: []string{
+					"allow_stopping_for_update",
+					"initial_password",
+					"public_key",
+					"user_data",
+					"user_data_base64",
+				},
+			},
+		},
+	})
+}
+
+func testAccCheckVMRegion(api *fakeAPI, resourceName string, region string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[resourceName]
+		if !ok {
+			return fmt.Errorf("not found: %s", resourceName)
+		}
+		if got := api.region(rs.Primary.ID); got != region {
+			return fmt.Errorf("VM %s was created in %q, expected %q", rs.Primary.ID, got, region)
+		}
+		return nil
+	}
+}
+
+func testAccVMImportStateIdWithRegion(resourceName string) resource.ImportStateIdFunc {
+	return func(s *terraform.State) (string, error) {
+		rs, ok := s.RootModule().Resources[resourceName]
+		if !ok {
+			return "", fmt.Errorf("not found: %s", resourceName)
+		}
+		return rs.Primary.Attributes["region"] + "/" + rs.Primary.ID, nil
+	}
+}
+
+func TestAccIdcloudhostVM_invalidOSImage(t *testing.T) {
+	api := newFakeAPI(t)
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: testAccProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config:      testAccVMConfigOSImage(api, "ubuntu", "22.10"),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile(`os_version "22.10" of "ubuntu" is not available`),
+			},
+			{
+				Config:      testAccVMConfigOSImage(api, "ubunt", "20.04"),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile(`os_name "ubunt" is not available`),
+			},
+		},
+	})
+}
+
+func TestAccIdcloudhostVM_backup(t *testing.T) {
+	api := newFakeAPI(t)
+	resourceName := "idcloudhost_vm.test"
+	var vmUUID string
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: testAccProviderFactories,
+		CheckDestroy:      testAccCheckVMDestroy(api),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccVMConfigBackup(api, true),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(resourceName, "backup", "true"),
+					func(s *terraform.State) error {
+						vmUUID = s.RootModule().Resources[resourceName].Primary.ID
+						return nil
+					},
+				),
+			},
+			// backup disabled outside of Terraform is enabled again
+			{
+				PreConfig: func() {
+					api.setBackup(vmUUID, false)
+				},
+				Config: testAccVMConfigBackup(api, true),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(resourceName, "backup", "true"),
+					testAccCheckVMBackup(api, resourceName, true),
+				),
+			},
+			{
+				Config: testAccVMConfigBackup(api, false),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(resourceName, "backup", "false"),
+					testAccCheckVMBackup(api, resourceName, false),
+				),
+			},
+		},
+	})
+}
+
+func TestSetVMBackup(t *testing.T) {
+	api := newFakeAPI(t)
+	vm := api.addVM("web")
+	c, err := api.config().Client("")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// setting the same value twice must not toggle it back
+	for _, enabled := range []bool{true, true, false, false} {
+		if err := setVMBackup(c.VM, vm.UUID, enabled); err != nil {
+			t.Fatalf("setVMBackup(%t): %s", enabled, err)
+		}
+		if got := api.vm(vm.UUID).Backup; got != enabled {
+			t.Errorf("setVMBackup(%t): backup is %t", enabled, got)
+		}
+	}
+}
+
+func testAccCheckVMBackup(api *fakeAPI, resourceName string, enabled bool) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[resourceName]
+		if !ok {
+			return fmt.Errorf("not found: %s", resourceName)
+		}
+		vm := api.vm(rs.Primary.ID)
+		if vm == nil {
+			return fmt.Errorf("VM %s does not exist in the API", rs.Primary.ID)
+		}
+		if vm.Backup != enabled {
+			return fmt.Errorf("backup of VM %s is %t, expected %t", rs.Primary.ID, vm.Backup, enabled)
+		}
+		return nil
+	}
+}
+
+func testAccVMConfig(api *fakeAPI, name string, vcpu int, memory int, disks int) string {
+	return api.providerConfig() + fmt.Sprintf(`
+resource "idcloudhost_vm" "test" {
+  name                      = %q
+  os_name                   = "ubuntu"
+  os_version                = "20.04"
+  disks                     = %d
+  vcpu                      = %d
+  memory                    = %d
+  username                  = "example"
+  initial_password          = "Password123"
+  billing_account_id        = %d
+  allow_stopping_for_update = true
+}
+`, name, disks, vcpu, memory, fakeAPIBillingAccountID)
+}
+
+func testAccVMConfigPowerState(api *fakeAPI, powerState string) string {
+	return api.providerConfig() + fmt.Sprintf(`
+resource "idcloudhost_vm" "test" {
+  name               = "testvm"
+  os_name            = "ubuntu"
+  os_version         = "20.04"
+  disks              = 20
+  vcpu               = 1
+  memory             = 1024
+  username           = "example"
+  initial_password   = "Password123"
+  billing_account_id = %d
+  power_state        = %q
+}
+`, fakeAPIBillingAccountID, powerState)
+}
+
+func testAccVMConfigOSImage(api *fakeAPI, osName string, osVersion string) string {
+	return api.providerConfig() + fmt.Sprintf(`
+resource "idcloudhost_vm" "test" {
+  name               = "testvm"
+  os_name            = %q
+  os_version         = %q
+  disks              = 20
+  vcpu               = 1
+  memory             = 1024
+  username           = "example"
+  initial_password   = "Password123"
+  billing_account_id = %d
+}
+`, osName, osVersion, fakeAPIBillingAccountID)
+}
+
+func testAccVMConfigRegion(api *fakeAPI, region string) string {
+	return api.providerConfig() + fmt.Sprintf(`
+resource "idcloudhost_vm" "test" {
+  name               = "testvm"
+  os_name            = "ubuntu"
+  os_version         = "20.04"
+  disks              = 20
+  vcpu               = 1
+  memory             = 1024
+  username           = "example"
+  initial_password   = "Password123"
+  billing_account_id = %d
+  region             = %q
+}
+`, fakeAPIBillingAccountID, region)
+}
+
+func testAccVMConfigBackup(api *fakeAPI, backup bool) string {
+	return api.providerConfig() + fmt.Sprintf(`
+resource "idcloudhost_vm" "test" {
+  name               = "testvm"
+  os_name            = "ubuntu"
+  os_version         = "20.04"
+  disks              = 20
+  vcpu               = 1
+  memory             = 1024
+  username           = "example"
+  initial_password   = "Password123"
+  billing_account_id = %d
+  backup             = %t
+}
+`, fakeAPIBillingAccountID, backup)
+}
+
+// testVMRawConfig returns the configuration of a small VM with overrides
+// applied, for testResourceApply.
+func testVMRawConfig(overrides map[string]interface{}) map[string]interface{} {
+	raw := map[string]interface{}{
+		"name":               "testvm",
+		"os_name":            "ubuntu",
+		"os_version":         "20.04",
+		"disks":              20,
+		"vcpu":               1,
+		"memory":             1024,
+		"username":           "example",
+		"initial_password":   "Password123",
+		"billing_account_id": fakeAPIBillingAccountID,
+	}
+	for key, value := range overrides {
+		raw[key] = value
+	}
+	return raw
+}
+
+func TestResourceVirtualMachine_powerState(t *testing.T) {
+	api := newFakeAPI(t)
+	cfg := api.config()
+	r := resourceVirtualMachine()
+
+	state, diags := testResourceApply(t, r, nil, testVMRawConfig(map[string]interface{}{"power_state": "stopped"}), cfg)
+	if diags.HasError() {
+		t.Fatalf("unable to create VM: %v", diags)
+	}
+	if got := api.vm(state.ID).Status; got != vmStatusStopped {
+		t.Fatalf("new VM is %s, want it stopped", got)
+	}
+	if got := state.Attributes["power_state"]; got != vmStatusStopped {
+		t.Errorf("power_state is %q after create, want %q", got, vmStatusStopped)
+	}
+
+	state, diags = testResourceApply(t, r, state, testVMRawConfig(map[string]interface{}{"power_state": "running"}), cfg)
+	if diags.HasError() {
+		t.Fatalf("unable to start VM: %v", diags)
+	}
+	if got := api.vm(state.ID).Status; got != vmStatusRunning {
+		t.Errorf("VM is %s, want it running", got)
+	}
+	if got := state.Attributes["status"]; got != vmStatusRunning {
+		t.Errorf("status is %q after update, want %q", got, vmStatusRunning)
+	}
+
+	// without power_state the current status is left alone
+	state, diags = testResourceApply(t, r, state, testVMRawConfig(map[string]interface{}{"name": "renamed"}), cfg)
+	if diags.HasError() {
+		t.Fatalf("unable to rename VM: %v", diags)
+	}
+	if got := api.vm(state.ID).Status; got != vmStatusRunning {
+		t.Errorf("VM is %s after an update without power_state, want it running", got)
+	}
+}
+
+func TestResourceVirtualMachine_resize(t *testing.T) {
+	api := newFakeAPI(t)
+	cfg := api.config()
+	r := resourceVirtualMachine()
+
+	state, diags := testResourceApply(t, r, nil, testVMRawConfig(nil), cfg)
+	if diags.HasError() {
+		t.Fatalf("unable to create VM: %v", diags)
+	}
+
+	// a running VM is not stopped unless allowed
+	_, diags = testResourceApply(t, r, state, testVMRawConfig(map[string]interface{}{"vcpu": 2}), cfg)
+	if !diags.HasError() || !strings.Contains(fmt.Sprint(diags), "allow_stopping_for_update") {
+		t.Fatalf("resizing a running VM without allow_stopping_for_update: got %v, want an error", diags)
+	}
+	if vm := api.vm(state.ID); vm.VCPU != 1 || vm.Status != vmStatusRunning {
+		t.Errorf("VM has %d vCPU and is %s, want it untouched", vm.VCPU, vm.Status)
+	}
+
+	// a failed resize does not leave the VM stopped
+	api.failVMRequests(http.MethodPatch, http.StatusBadRequest, "not enough capacity")
+	_, diags = testResourceApply(t, r, state, testVMRawConfig(map[string]interface{}{"vcpu": 2, "allow_stopping_for_update": true}), cfg)
+	if !diags.HasError() {
+		t.Fatal("resize succeeded although the API failed it")
+	}
+	if got := api.vm(state.ID).Status; got != vmStatusRunning {
+		t.Errorf("VM is %s after a failed resize, want it started again", got)
+	}
+	api.failVMRequests(http.MethodPatch, 0, "")
+
+	state, diags = testResourceApply(t, r, state, testVMRawConfig(map[string]interface{}{"vcpu": 2, "memory": 2048, "allow_stopping_for_update": true}), cfg)
+	if diags.HasError() {
+		t.Fatalf("unable to resize VM: %v", diags)
+	}
+	vm := api.vm(state.ID)
+	if vm.VCPU != 2 || vm.Memory != 2048 {
+		t.Errorf("VM has %d vCPU and %d MB, want 2 and 2048", vm.VCPU, vm.Memory)
+	}
+	if vm.Status != vmStatusRunning {
+		t.Errorf("VM is %s after the resize, want it started again", vm.Status)
+	}
+
+	// a VM stopped by power_state stays stopped
+	state, diags = testResourceApply(t, r, state, testVMRawConfig(map[string]interface{}{"vcpu": 4, "power_state": "stopped"}), cfg)
+	if diags.HasError() {
+		t.Fatalf("unable to resize stopped VM: %v", diags)
+	}
+	if vm := api.vm(state.ID); vm.VCPU != 4 || vm.Status != vmStatusStopped {
+		t.Errorf("VM has %d vCPU and is %s, want 4 and stopped", vm.VCPU, vm.Status)
+	}
+}
+
+func TestResourceVirtualMachine_userData(t *testing.T) {
+	api := newFakeAPI(t)
+	cfg := api.config()
+	r := resourceVirtualMachine()
+	// valid base64, but meant as plain text
+	script := "echo"
+	encoded := base64.StdEncoding.EncodeToString([]byte("#cloud-config\npackages: [nginx]\n"))
+
+	state, diags := testResourceApply(t, r, nil, testVMRawConfig(map[string]interface{}{"user_data": script}), cfg)
+	if diags.HasError() {
+		t.Fatalf("unable to create VM: %v", diags)
+	}
+	if got := api.vmUserData(state.ID); got != script {
+		t.Errorf("VM was created with user data %q, want %q sent as is", got, script)
+	}
+	if got := state.Attributes["user_data"]; got != hashUserData(script) {
+		t.Errorf("user_data in state is %q, want the hash of the script", got)
+	}
+
+	state, diags = testResourceApply(t, r, nil, testVMRawConfig(map[string]interface{}{"user_data_base64": encoded}), cfg)
+	if diags.HasError() {
+		t.Fatalf("unable to create VM: %v", diags)
+	}
+	if got := api.vmUserData(state.ID); got != "#cloud-config\npackages: [nginx]\n" {
+		t.Errorf("VM was created with user data %q, want the decoded user_data_base64", got)
+	}
+
+	_, diags = testResourceApply(t, r, nil, testVMRawConfig(map[string]interface{}{"user_data_base64": "#cloud-config"}), cfg)
+	if !diags.HasError() || !strings.Contains(fmt.Sprint(diags), "must be base64 encoded") {
+		t.Errorf("got %v for plain text user_data_base64, want it rejected", diags)
+	}
+	_, diags = testResourceApply(t, r, nil, testVMRawConfig(map[string]interface{}{"user_data": script, "user_data_base64": encoded}), cfg)
+	if !diags.HasError() {
+		t.Error("setting both user_data and user_data_base64 succeeded, want a conflict")
+	}
+}
+
+func TestResourceVirtualMachine_disks(t *testing.T) {
+	api := newFakeAPI(t)
+	cfg := api.config()
+	r := resourceVirtualMachine()
+
+	state, diags := testResourceApply(t, r, nil, testVMRawConfig(nil), cfg)
+	if diags.HasError() {
+		t.Fatalf("unable to create VM: %v", diags)
+	}
+	uuid := state.ID
+
+	// growing the boot disk resizes it in place
+	state, diags = testResourceApply(t, r, state, testVMRawConfig(map[string]interface{}{"disks": 40}), cfg)
+	if diags.HasError() {
+		t.Fatalf("unable to grow boot disk: %v", diags)
+	}
+	if state.ID != uuid {
+		t.Errorf("VM was replaced by %s, want %s resized in place", state.ID, uuid)
+	}
+	if got := api.vm(uuid).Storage[0].SizeGB; got != 40 {
+		t.Errorf("boot disk has %d GB, want 40", got)
+	}
+	if state.Attributes["disks"] != "40" || state.Attributes["storage.0.size"] != "40" {
+		t.Errorf("got disks %s and storage.0.size %s in the state, want 40", state.Attributes["disks"], state.Attributes["storage.0.size"])
+	}
+
+	// shrinking it is rejected when planning
+	_, diags = testResourceApply(t, r, state, testVMRawConfig(map[string]interface{}{"disks": 30}), cfg)
+	if !diags.HasError() || !strings.Contains(fmt.Sprint(diags), "cannot be shrunk from 40 to 30 GB") {
+		t.Errorf("shrinking the boot disk: got %v, want it rejected", diags)
+	}
+	if got := api.vm(uuid).Storage[0].SizeGB; got != 40 {
+		t.Errorf("boot disk has %d GB after a rejected shrink, want 40", got)
+	}
+}
+
+// TestResourceVirtualMachineRead_errorMentioning404 checks that an error
+// which merely mentions 404 does not remove an existing VM from the state.
+func TestResourceVirtualMachineRead_errorMentioning404(t *testing.T) {
+	api := newFakeAPI(t)
+	cfg := api.config()
+	vm := api.addVM("web")
+	api.failVMRequests(http.MethodGet, http.StatusInternalServerError, "hypervisor hv-404 not found in cluster")
+
+	d := resourceVirtualMachine().TestResourceData()
+	d.SetId(vm.UUID)
+	diags := resourceVirtualMachineRead(context.Background(), d, cfg)
+	if !diags.HasError() {
+		t.Error("read succeeded, want the API error reported")
+	}
+	if d.Id() != vm.UUID {
+		t.Errorf("VM %s was removed from the state although it exists", vm.UUID)
+	}
+
+	api.failVMRequests(http.MethodGet, 0, "")
+	api.deleteVM(vm.UUID)
+	if diags := resourceVirtualMachineRead(context.Background(), d, cfg); diags.HasError() {
+		t.Fatalf("unable to read deleted VM: %v", diags)
+	}
+	if d.Id() != "" {
+		t.Errorf("deleted VM %s is still in the state", vm.UUID)
+	}
+}
+
+// TestResourceVirtualMachine_planAfterImport checks that neither the API
+// normalizing attributes nor initial_password missing from an imported state
+// plan a replacement of the VM.
+func TestResourceVirtualMachine_planAfterImport(t *testing.T) {
+	api := newFakeAPI(t)
+	cfg := api.config()
+	r := resourceVirtualMachine()
+	ctx := context.Background()
+	raw := testVMRawConfig(map[string]interface{}{
+		"username":   "Example",
+		"public_key": "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5 example",
+		"user_data":  "#cloud-config\npackages:\n  - nginx\n",
+	})
+	config := terraform.NewResourceConfigRaw(raw)
+
+	created, diags := testResourceApply(t, r, nil, raw, cfg)
+	if diags.HasError() {
+		t.Fatalf("unable to create VM: %v", diags)
+	}
+	if got := api.vm(created.ID).Username; got != "example" {
+		t.Fatalf("got username %q from the API, want it normalized to %q", got, "example")
+	}
+	diff, err := r.Diff(ctx, created, config, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !diff.Empty() {
+		t.Errorf("got a diff after create: %v", diff)
+	}
+
+	d := r.Data(&terraform.InstanceState{ID: cfg.Region + "/" + created.ID})
+	imported, err := r.Importer.StateContext(ctx, d, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diags := r.ReadContext(ctx, imported[0], cfg); diags.HasError() {
+		t.Fatalf("unable to read imported VM: %v", diags)
+	}
+	// public_key and user_data are left out of the configuration of
+	// imported VMs, as they force a new VM
+	importRaw := testVMRawConfig(map[string]interface{}{"username": "Example"})
+	diff, err = r.Diff(ctx, imported[0].State(), terraform.NewResourceConfigRaw(importRaw), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !diff.Empty() {
+		t.Errorf("got a diff after import: %v", diff)
+	}
+}
+
+// TestResourceVirtualMachine_createOnlyAttributes checks that setting an
+// optional create-only attribute on an existing VM plans a replacement
+// instead of silently dropping the value.
+func TestResourceVirtualMachine_createOnlyAttributes(t *testing.T) {
+	api := newFakeAPI(t)
+	cfg := api.config()
+	r := resourceVirtualMachine()
+
+	state, diags := testResourceApply(t, r, nil, testVMRawConfig(nil), cfg)
+	if diags.HasError() {
+		t.Fatalf("unable to create VM: %v", diags)
+	}
+	for key, value := range map[string]interface{}{
+		"public_key":       "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5 example",
+		"user_data":        "#cloud-config\npackages:\n  - nginx\n",
+		"user_data_base64": base64.StdEncoding.EncodeToString([]byte("#cloud-config\n")),
+	} {
+		config := terraform.NewResourceConfigRaw(testVMRawConfig(map[string]interface{}{key: value}))
+		diff, err := r.Diff(context.Background(), state, config, cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff == nil || !diff.RequiresNew() {
+			t.Errorf("setting %s on an existing VM: got diff %v, want a replacement", key, diff)
+		}
+	}
+
+	// a changed initial_password replaces the VM, only an empty one from an
+	// import is ignored
+	config := terraform.NewResourceConfigRaw(testVMRawConfig(map[string]interface{}{"initial_password": "Password456"}))
+	diff, err := r.Diff(context.Background(), state, config, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff == nil || !diff.RequiresNew() {
+		t.Errorf("changing initial_password: got diff %v, want a replacement", diff)
+	}
+}
+
+func TestResourceVirtualMachineRead_networkUUID(t *testing.T) {
+	api := newFakeAPI(t)
+	cfg := api.config()
+	r := resourceVirtualMachine()
+	ctx := context.Background()
+	vm := api.addVM("web")
+	frontend := api.addNetwork("frontend", "jkt01")
+	backend := api.addNetwork("backend", "jkt01")
+
+	// VMs without a network in their state do not need the network list
+	api.failNetworkListing(http.StatusInternalServerError, "network service unavailable")
+	d := r.TestResourceData()
+	d.SetId(vm.UUID)
+	if diags := resourceVirtualMachineRead(ctx, d, cfg); diags.HasError() {
+		t.Fatalf("unable to read VM without network_uuid: %v", diags)
+	}
+	if got := d.Get("network_uuid").(string); got != "" {
+		t.Errorf("got network_uuid %q, want it left empty", got)
+	}
+
+	// a failing lookup keeps the previous network
+	if err := d.Set("network_uuid", frontend.UUID); err != nil {
+		t.Fatal(err)
+	}
+	if diags := resourceVirtualMachineRead(ctx, d, cfg); diags.HasError() {
+		t.Fatalf("unable to read VM while networks cannot be listed: %v", diags)
+	}
+	if got := d.Get("network_uuid").(string); got != frontend.UUID {
+		t.Errorf("got network_uuid %q, want %q kept", got, frontend.UUID)
+	}
+
+	api.failNetworkListing(0, "")
+	api.moveVM(vm.UUID, backend.UUID)
+	if diags := resourceVirtualMachineRead(ctx, d, cfg); diags.HasError() {
+		t.Fatalf("unable to read VM: %v", diags)
+	}
+	if got := d.Get("network_uuid").(string); got != backend.UUID {
+		t.Errorf("got network_uuid %q, want %q", got, backend.UUID)
+	}
+
+	// imported VMs look their network up once
+	d = r.Data(&terraform.InstanceState{ID: vm.UUID})
+	if _, err := r.Importer.StateContext(ctx, d, cfg); err != nil {
+		t.Fatal(err)
+	}
+	if got := d.Get("network_uuid").(string); got != backend.UUID {
+		t.Errorf("got network_uuid %q after import, want %q", got, backend.UUID)
+	}
+}
+
+// TestResourceVirtualMachine_sourceReplica checks that source_replica is also
+// validated when it changes on an existing VM, which replaces the VM.
+func TestResourceVirtualMachine_sourceReplica(t *testing.T) {
+	api := newFakeAPI(t)
+	cfg := api.config()
+	r := resourceVirtualMachine()
+	vm := api.addVM("golden")
+	small := api.addSnapshot("small", vm.UUID, 20)
+	large := api.addSnapshot("large", vm.UUID, 40)
+
+	state, diags := testResourceApply(t, r, nil, testVMRawConfig(map[string]interface{}{"source_replica": small.UUID}), cfg)
+	if diags.HasError() {
+		t.Fatalf("unable to clone VM: %v", diags)
+	}
+	uuid := state.ID
+
+	_, diags = testResourceApply(t, r, state, testVMRawConfig(map[string]interface{}{"source_replica": large.UUID}), cfg)
+	if !diags.HasError() || !strings.Contains(fmt.Sprint(diags), "disks must be at least the 40 GB of snapshot") {
+		t.Fatalf("cloning a larger snapshot with a smaller disk: got %v, want it rejected when planning", diags)
+	}
+	if api.vm(uuid) == nil {
+		t.Errorf("VM %s was destroyed although the plan was rejected", uuid)
+	}
+
+	_, diags = testResourceApply(t, r, state, testVMRawConfig(map[string]interface{}{"source_replica": "00000000-0000-4000-8000-000000000000"}), cfg)
+	if !diags.HasError() || !strings.Contains(fmt.Sprint(diags), "does not exist") {
+		t.Errorf("cloning a missing snapshot: got %v, want it rejected when planning", diags)
+	}
+}
@@ -0,0 +1,258 @@
+package idcloudhost
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"math"
+	"math/rand"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// endpointTransport redirects requests aimed at the public IDCloudHost API to
+// the configured api_url, so the client library can be pointed at a staging
+// or fake endpoint without knowing about it.
+type endpointTransport struct {
+	endpoint *url.URL
+	next     http.RoundTripper
+}
+
+func (t *endpointTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	defaultURL, _ := url.Parse(defaultAPIURL)
+	if req.URL.Host != defaultURL.Host {
+		return t.next.RoundTrip(req)
+	}
+	req = req.Clone(req.Context())
+	req.URL.Scheme = t.endpoint.Scheme
+	req.URL.Host = t.endpoint.Host
+	req.URL.Path = strings.TrimSuffix(t.endpoint.Path, "/") + req.URL.Path
+	req.Host = t.endpoint.Host
+	return t.next.RoundTrip(req)
+}
+
+// timeoutTransport bounds every request attempt, reading the response body
+// included, by timeout. It sits below retryTransport so that an attempt that
+// timed out can be retried with a fresh deadline.
+type timeoutTransport struct {
+	next    http.RoundTripper
+	timeout time.Duration
+}
+
+func (t *timeoutTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx, cancel := context.WithTimeout(req.Context(), t.timeout)
+	resp, err := t.next.RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	resp.Body = &cancelOnCloseBody{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+// cancelOnCloseBody releases the attempt deadline once the body is closed.
+type cancelOnCloseBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelOnCloseBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
+
+// retryTransport retries requests that failed with a transient error.
+//
+// Reads (and other idempotent methods) are retried on network errors and on
+// 5xx responses. Non-idempotent requests such as creates are only retried when
+// the API could not have acted on them: a 429 response or a failure to
+// connect, so a flaky API never ends up creating the same resource twice.
+type retryTransport struct {
+	next         http.RoundTripper
+	maxRetries   int
+	retryWaitMin time.Duration
+	retryWaitMax time.Duration
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	idempotent := isIdempotentMethod(req.Method)
+	attemptReq := req
+	for attempt := 0; ; attempt++ {
+		if attempt > 0 && req.Body != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			attemptReq = req.Clone(req.Context())
+			attemptReq.Body = body
+		}
+
+		resp, err := t.next.RoundTrip(attemptReq)
+
+		canRewind := req.Body == nil || req.GetBody != nil
+		if attempt >= t.maxRetries || !canRewind || !shouldRetry(req, resp, err, idempotent) {
+			return resp, err
+		}
+
+		wait := t.backoff(attempt, resp)
+		if resp != nil {
+			log.Printf("[WARN] %s %s returned %d, retrying in %s (%d/%d)", req.Method, req.URL.Path, resp.StatusCode, wait, attempt+1, t.maxRetries)
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		} else {
+			log.Printf("[WARN] %s %s failed: %s, retrying in %s (%d/%d)", req.Method, req.URL.Path, err, wait, attempt+1, t.maxRetries)
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// backoff returns how long to wait before the next attempt. A Retry-After
+// header sent by the API takes precedence over the exponential schedule, both
+// are capped at retryWaitMax.
+func (t *retryTransport) backoff(attempt int, resp *http.Response) time.Duration {
+	if resp != nil {
+		if wait, ok := parseRetryAfter(resp.Header.Get("Retry-After")); ok {
+			if wait > t.retryWaitMax {
+				return t.retryWaitMax
+			}
+			return wait
+		}
+	}
+	wait := time.Duration(float64(t.retryWaitMin) * math.Pow(2, float64(attempt)))
+	if wait <= 0 || wait > t.retryWaitMax {
+		wait = t.retryWaitMax
+	}
+	// add up to 20% jitter so parallel resources do not retry in lockstep
+	if jitter := int64(wait) / 5; jitter > 0 {
+		wait += time.Duration(rand.Int63n(jitter))
+	}
+	if wait > t.retryWaitMax {
+		wait = t.retryWaitMax
+	}
+	return wait
+}
+
+func shouldRetry(req *http.Request, resp *http.Response, err error, idempotent bool) bool {
+	if req.Context().Err() != nil {
+		return false
+	}
+	if err != nil {
+		if idempotent {
+			return true
+		}
+		// the request never left the machine, so it is safe to send again
+		var opErr *net.OpError
+		return errors.As(err, &opErr) && opErr.Op == "dial"
+	}
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return true
+	}
+	if !idempotent {
+		return false
+	}
+	switch resp.StatusCode {
+	case http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+func isIdempotentMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		wait := time.Until(date)
+		if wait < 0 {
+			wait = 0
+		}
+		return wait, true
+	}
+	return 0, false
+}
+
+// rateLimitTransport delays requests so that all resources sharing the
+// provider's HTTP client stay below the configured request rate.
+type rateLimitTransport struct {
+	next    http.RoundTripper
+	limiter *tokenBucket
+}
+
+func (t *rateLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := t.limiter.Wait(req.Context()); err != nil {
+		return nil, err
+	}
+	return t.next.RoundTrip(req)
+}
+
+// tokenBucket is a minimal token bucket limiter: it holds up to burst tokens
+// and refills at rate tokens per second.
+type tokenBucket struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(rate float64, burst int) *tokenBucket {
+	if burst < 1 {
+		burst = 1
+	}
+	return &tokenBucket{
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// Wait blocks until a token is available or ctx is done.
+func (b *tokenBucket) Wait(ctx context.Context) error {
+	for {
+		b.mu.Lock()
+		now := time.Now()
+		b.tokens = math.Min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+		b.last = now
+		if b.tokens >= 1 {
+			b.tokens--
+			b.mu.Unlock()
+			return nil
+		}
+		wait := time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+		b.mu.Unlock()
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
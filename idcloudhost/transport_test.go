@@ -0,0 +1,313 @@
+package idcloudhost
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newTestHTTPClient returns the provider HTTP client with short retry waits.
+func newTestHTTPClient(t *testing.T, maxRetries int, requestTimeout time.Duration) *http.Client {
+	t.Helper()
+	cfg := &Config{
+		RequestTimeout: requestTimeout,
+		MaxRetries:     maxRetries,
+		RetryWaitMin:   time.Millisecond,
+		RetryWaitMax:   10 * time.Millisecond,
+	}
+	httpClient, err := cfg.newHTTPClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return httpClient
+}
+
+// failingServer answers the first failures requests with status and the
+// following ones with 200, echoing the request body.
+func failingServer(t *testing.T, failures int32, status int) (*httptest.Server, *int32) {
+	t.Helper()
+	var attempts int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if atomic.AddInt32(&attempts, 1) <= failures {
+			w.WriteHeader(status)
+			return
+		}
+		w.Write(body)
+	}))
+	t.Cleanup(server.Close)
+	return server, &attempts
+}
+
+func TestRetryTransport(t *testing.T) {
+	cases := []struct {
+		name         string
+		method       string
+		status       int
+		failures     int32
+		wantStatus   int
+		wantAttempts int32
+	}{
+		{"get retried on 5xx", http.MethodGet, http.StatusServiceUnavailable, 2, http.StatusOK, 3},
+		{"get gives up after max retries", http.MethodGet, http.StatusBadGateway, 10, http.StatusBadGateway, 4},
+		{"get not retried on 4xx", http.MethodGet, http.StatusNotFound, 1, http.StatusNotFound, 1},
+		{"post not retried on 5xx", http.MethodPost, http.StatusInternalServerError, 1, http.StatusInternalServerError, 1},
+		{"post retried on 429", http.MethodPost, http.StatusTooManyRequests, 2, http.StatusOK, 3},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			server, attempts := failingServer(t, tc.failures, tc.status)
+			httpClient := newTestHTTPClient(t, 3, time.Minute)
+
+			req, err := http.NewRequest(tc.method, server.URL, strings.NewReader("name=test"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp, err := httpClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			body, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+
+			if resp.StatusCode != tc.wantStatus {
+				t.Errorf("got status %d, want %d", resp.StatusCode, tc.wantStatus)
+			}
+			if got := atomic.LoadInt32(attempts); got != tc.wantAttempts {
+				t.Errorf("got %d attempts, want %d", got, tc.wantAttempts)
+			}
+			// the body must be sent again on every retry
+			if resp.StatusCode == http.StatusOK && string(body) != "name=test" {
+				t.Errorf("got body %q on the last attempt, want %q", body, "name=test")
+			}
+		})
+	}
+}
+
+func TestRetryTransportBackoff(t *testing.T) {
+	transport := &retryTransport{
+		retryWaitMin: time.Second,
+		retryWaitMax: 10 * time.Second,
+	}
+	for attempt, want := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second} {
+		got := transport.backoff(attempt, nil)
+		// up to 20% jitter is added
+		if got < want || got > want+want/5 {
+			t.Errorf("backoff(%d) = %s, want between %s and %s", attempt, got, want, want+want/5)
+		}
+	}
+	if got := transport.backoff(10, nil); got != 10*time.Second {
+		t.Errorf("backoff(10) = %s, want it capped at %s", got, 10*time.Second)
+	}
+
+	retryAfter := func(value string) *http.Response {
+		return &http.Response{Header: http.Header{"Retry-After": {value}}}
+	}
+	if got := transport.backoff(0, retryAfter("3")); got != 3*time.Second {
+		t.Errorf("backoff with Retry-After 3 = %s, want 3s", got)
+	}
+	if got := transport.backoff(0, retryAfter("120")); got != 10*time.Second {
+		t.Errorf("backoff with Retry-After 120 = %s, want it capped at 10s", got)
+	}
+	if got := transport.backoff(1, retryAfter("soon")); got < 2*time.Second {
+		t.Errorf("backoff with invalid Retry-After = %s, want the exponential schedule", got)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	if wait, ok := parseRetryAfter("5"); !ok || wait != 5*time.Second {
+		t.Errorf("parseRetryAfter(\"5\") = %s, %t", wait, ok)
+	}
+	date := time.Now().Add(time.Hour).UTC().Format(http.TimeFormat)
+	if wait, ok := parseRetryAfter(date); !ok || wait < 59*time.Minute || wait > time.Hour {
+		t.Errorf("parseRetryAfter(%q) = %s, %t", date, wait, ok)
+	}
+	for _, value := range []string{"", "-1", "soon"} {
+		if _, ok := parseRetryAfter(value); ok {
+			t.Errorf("parseRetryAfter(%q) succeeded, want it rejected", value)
+		}
+	}
+}
+
+// TestTimeoutTransport_body checks that request_timeout also bounds reading a
+// response whose body never completes.
+func TestTimeoutTransport_body(t *testing.T) {
+	done := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("{"))
+		w.(http.Flusher).Flush()
+		select {
+		case <-done:
+		case <-r.Context().Done():
+		}
+	}))
+	t.Cleanup(server.Close)
+	t.Cleanup(func() { close(done) })
+	httpClient := newTestHTTPClient(t, 0, 100*time.Millisecond)
+
+	start := time.Now()
+	resp, err := httpClient.Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if _, err := io.ReadAll(resp.Body); err == nil {
+		t.Fatal("reading the body succeeded, want a timeout")
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("reading the body took %s, want it bounded by request_timeout", elapsed)
+	}
+}
+
+// TestTimeoutTransport_retried checks that every attempt gets its own
+// deadline, so an attempt that timed out is retried.
+func TestTimeoutTransport_retried(t *testing.T) {
+	var attempts int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&attempts, 1) == 1 {
+			<-r.Context().Done()
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	t.Cleanup(server.Close)
+	httpClient := newTestHTTPClient(t, 2, 100*time.Millisecond)
+
+	resp, err := httpClient.Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil || string(body) != "ok" {
+		t.Errorf("got body %q, %v, want %q", body, err, "ok")
+	}
+	if got := atomic.LoadInt32(&attempts); got != 2 {
+		t.Errorf("got %d attempts, want 2", got)
+	}
+}
+
+func TestTokenBucket_burst(t *testing.T) {
+	bucket := newTokenBucket(1, 5)
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		if err := bucket.Wait(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed > 100*time.Millisecond {
+		t.Errorf("5 requests within the burst took %s, want no wait", elapsed)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := bucket.Wait(ctx); err != context.DeadlineExceeded {
+		t.Errorf("got %v for a request beyond the burst, want it to wait for the next token", err)
+	}
+}
+
+func TestTokenBucket_refill(t *testing.T) {
+	bucket := newTokenBucket(20, 1)
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		if err := bucket.Wait(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// the burst covers the first request, the other 4 wait 50ms each
+	if elapsed := time.Since(start); elapsed < 180*time.Millisecond || elapsed > time.Second {
+		t.Errorf("5 requests at 20 per second took %s, want about 200ms", elapsed)
+	}
+}
+
+func TestTokenBucket_canceled(t *testing.T) {
+	bucket := newTokenBucket(0.1, 1)
+	if err := bucket.Wait(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(20*time.Millisecond, cancel)
+	start := time.Now()
+	if err := bucket.Wait(ctx); err != context.Canceled {
+		t.Errorf("got %v, want %v", err, context.Canceled)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("canceled wait returned after %s, want it to return when canceled", elapsed)
+	}
+	if err := bucket.Wait(ctx); err != context.Canceled {
+		t.Errorf("got %v for an already canceled context, want %v", err, context.Canceled)
+	}
+}
+
+func TestTokenBucket_concurrent(t *testing.T) {
+	const rate, burst = 100, 5
+	bucket := newTokenBucket(rate, burst)
+	var mu sync.Mutex
+	var granted []time.Time
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 3; j++ {
+				if err := bucket.Wait(context.Background()); err != nil {
+					t.Error(err)
+					return
+				}
+				mu.Lock()
+				granted = append(granted, time.Now())
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	sort.Slice(granted, func(i, j int) bool { return granted[i].Before(granted[j]) })
+	// any window may hold the burst plus what was refilled during it, with one
+	// token of slack for timer granularity
+	for i := range granted {
+		for j := i; j < len(granted); j++ {
+			allowed := burst + rate*granted[j].Sub(granted[i]).Seconds() + 1
+			if count := float64(j - i + 1); count > allowed {
+				t.Fatalf("%.0f requests granted within %s, want at most %.1f", count, granted[j].Sub(granted[i]), allowed)
+			}
+		}
+	}
+}
+
+// TestRateLimitTransport_canceled checks that a request canceled while
+// waiting for the rate limit is never sent.
+func TestRateLimitTransport_canceled(t *testing.T) {
+	server, attempts := failingServer(t, 0, http.StatusOK)
+	httpClient := &http.Client{
+		Transport: &rateLimitTransport{
+			next:    http.DefaultTransport,
+			limiter: newTokenBucket(0.1, 1),
+		},
+	}
+	resp, err := httpClient.Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+	if resp, err := httpClient.Do(req); err == nil {
+		resp.Body.Close()
+		t.Fatal("request succeeded beyond the rate limit, want it canceled")
+	}
+	if got := atomic.LoadInt32(attempts); got != 1 {
+		t.Errorf("got %d requests sent, want only the first one", got)
+	}
+}
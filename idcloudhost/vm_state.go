@@ -0,0 +1,168 @@
+package idcloudhost
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"time"
+
+	idcloudhostVM "github.com/bapung/idcloudhost-go-client-library/idcloudhost/vm"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+const (
+	vmStatusRunning = "running"
+	vmStatusStopped = "stopped"
+
+	// vmStatePending and vmStateReady are the states reported by
+	// vmProvisioningRefreshFunc, independent of the API status values.
+	vmStatePending = "pending"
+	vmStateReady   = "ready"
+)
+
+// vmPollDelay and vmPollMinTimeout control how often VM status is polled
+// while waiting for it to change.
+var (
+	vmPollDelay      = 5 * time.Second
+	vmPollMinTimeout = 3 * time.Second
+)
+
+// vmFailedStatuses are the statuses in which a VM will never finish
+// provisioning.
+var vmFailedStatuses = []string{"error", "failed"}
+
+// getVM fetches a VM through apiRequest rather than the client library, so a
+// missing VM can be recognized with isNotFoundError.
+func getVM(ctx context.Context, cfg *Config, region string, uuid string) (*idcloudhostVM.VM, error) {
+	var vm idcloudhostVM.VM
+	path := fmt.Sprintf("%s/user-resource/vm", region)
+	if err := cfg.apiRequest(ctx, http.MethodGet, path, url.Values{"uuid": {uuid}}, &vm); err != nil {
+		return nil, err
+	}
+	return &vm, nil
+}
+
+// vmStateRefreshFunc polls a VM and reports its status as the state.
+func vmStateRefreshFunc(cfg *Config, region string, uuid string) resource.StateRefreshFunc {
+	return func() (interface{}, string, error) {
+		c, err := cfg.Client(region)
+		if err != nil {
+			return nil, "", err
+		}
+		if err := c.VM.Get(uuid); err != nil {
+			return nil, "", err
+		}
+		vm := c.VM.VM
+		return &vm, vm.Status, nil
+	}
+}
+
+// vmProvisioningRefreshFunc polls a newly created VM and reports it ready once
+// it is running and has a private IPv4 address. Any other status is pending,
+// except for failed ones which stop the polling with an error.
+func vmProvisioningRefreshFunc(cfg *Config, region string, uuid string) resource.StateRefreshFunc {
+	return func() (interface{}, string, error) {
+		c, err := cfg.Client(region)
+		if err != nil {
+			return nil, "", err
+		}
+		if err := c.VM.Get(uuid); err != nil {
+			return nil, "", err
+		}
+		vm := c.VM.VM
+		for _, status := range vmFailedStatuses {
+			if vm.Status == status {
+				return &vm, vm.Status, fmt.Errorf("VM %s provisioning ended in status %q", uuid, vm.Status)
+			}
+		}
+		if vm.Status == vmStatusRunning && vm.PrivateIPv4 != "" {
+			return &vm, vmStateReady, nil
+		}
+		log.Printf("[DEBUG] VM %s is not ready yet, status: %q, private IPv4: %q", uuid, vm.Status, vm.PrivateIPv4)
+		return &vm, vmStatePending, nil
+	}
+}
+
+// waitForVMReady waits until a newly created VM is running and reachable on
+// its private network.
+func waitForVMReady(ctx context.Context, cfg *Config, region string, uuid string, timeout time.Duration) (*idcloudhostVM.VM, error) {
+	stateConf := &resource.StateChangeConf{
+		Pending:    []string{vmStatePending},
+		Target:     []string{vmStateReady},
+		Refresh:    vmProvisioningRefreshFunc(cfg, region, uuid),
+		Timeout:    timeout,
+		Delay:      2 * vmPollDelay,
+		MinTimeout: vmPollMinTimeout,
+	}
+	result, err := stateConf.WaitForStateContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error waiting for VM %s to be ready: %s", uuid, err)
+	}
+	return result.(*idcloudhostVM.VM), nil
+}
+
+// waitForVMStatus polls the VM until it reports the target status.
+func waitForVMStatus(ctx context.Context, cfg *Config, region string, uuid string, target string, timeout time.Duration) (*idcloudhostVM.VM, error) {
+	stateConf := &resource.StateChangeConf{
+		Pending:    []string{"starting", "stopping", "running", "stopped", "paused"},
+		Target:     []string{target},
+		Refresh:    vmStateRefreshFunc(cfg, region, uuid),
+		Timeout:    timeout,
+		Delay:      vmPollDelay,
+		MinTimeout: vmPollMinTimeout,
+	}
+	result, err := stateConf.WaitForStateContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error waiting for VM %s to become %s: %s", uuid, target, err)
+	}
+	return result.(*idcloudhostVM.VM), nil
+}
+
+// setVMPowerState starts or stops the VM and waits until it reached the
+// requested status.
+func setVMPowerState(ctx context.Context, cfg *Config, region string, uuid string, target string, timeout time.Duration) error {
+	var action string
+	switch target {
+	case vmStatusRunning:
+		action = "start"
+	case vmStatusStopped:
+		action = "stop"
+	default:
+		return fmt.Errorf("unsupported power state %q", target)
+	}
+
+	log.Printf("[INFO] Setting power state of VM %s to %s", uuid, target)
+	path := fmt.Sprintf("%s/user-resource/vm/%s", region, action)
+	if err := cfg.apiRequest(ctx, http.MethodPost, path, url.Values{"uuid": {uuid}}, nil); err != nil {
+		return fmt.Errorf("unable to %s VM %s: %s", action, uuid, err)
+	}
+	_, err := waitForVMStatus(ctx, cfg, region, uuid, target, timeout)
+	return err
+}
+
+// setVMBackup enables or disables automatic backups of the VM. The API only
+// offers a toggle, so it is called only when the current value differs from
+// the requested one, which keeps a drifted value from being inverted. The
+// toggle does not return the VM, so it is fetched again to check the result.
+func setVMBackup(vmApi *idcloudhostVM.VirtualMachineAPI, uuid string, enabled bool) error {
+	if err := vmApi.Get(uuid); err != nil {
+		return fmt.Errorf("unable to get VM %s: %s", uuid, err)
+	}
+	if vmApi.VM.Backup == enabled {
+		log.Printf("[DEBUG] Backup of VM %s is already %t", uuid, enabled)
+		return nil
+	}
+	log.Printf("[INFO] Setting backup of VM %s to %t", uuid, enabled)
+	if err := vmApi.ToggleAutoBackup(uuid); err != nil {
+		return fmt.Errorf("unable to toggle auto backup of VM %s: %s", uuid, err)
+	}
+	if err := vmApi.Get(uuid); err != nil {
+		return fmt.Errorf("unable to get VM %s after toggling its backup: %s", uuid, err)
+	}
+	if vmApi.VM.Backup != enabled {
+		return fmt.Errorf("backup of VM %s is %t after toggling it, expected %t", uuid, vmApi.VM.Backup, enabled)
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package idcloudhost
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	idcloudhostVM "github.com/bapung/idcloudhost-go-client-library/idcloudhost/vm"
+)
+
+func TestSetVMPowerState(t *testing.T) {
+	api := newFakeAPI(t)
+	cfg := api.config()
+	vm := api.addVM("web")
+	ctx := context.Background()
+
+	for _, target := range []string{vmStatusStopped, vmStatusStopped, vmStatusRunning} {
+		if err := setVMPowerState(ctx, cfg, cfg.Region, vm.UUID, target, time.Minute); err != nil {
+			t.Fatalf("setVMPowerState(%s): %s", target, err)
+		}
+		if got := api.vm(vm.UUID).Status; got != target {
+			t.Errorf("setVMPowerState(%s): VM is %s", target, got)
+		}
+	}
+
+	if err := setVMPowerState(ctx, cfg, cfg.Region, vm.UUID, "paused", time.Minute); err == nil {
+		t.Error("setVMPowerState(paused) succeeded, want an unsupported power state error")
+	}
+	if err := setVMPowerState(ctx, cfg, cfg.Region, "00000000-0000-4000-8000-000000000000", vmStatusStopped, time.Minute); err == nil {
+		t.Error("setVMPowerState of a missing VM succeeded, want an error")
+	}
+}
+
+func TestWaitForVMReady(t *testing.T) {
+	api := newFakeAPI(t)
+	cfg := api.config()
+	api.slowProvisioning(3, vmStatusRunning)
+
+	state, diags := testResourceApply(t, resourceVirtualMachine(), nil, testVMRawConfig(nil), cfg)
+	if diags.HasError() {
+		t.Fatalf("unable to create VM: %v", diags)
+	}
+	if state.Attributes["status"] != vmStatusRunning || state.Attributes["private_ipv4"] == "" {
+		t.Errorf("create finished with status %q and private IPv4 %q, want it running with an address", state.Attributes["status"], state.Attributes["private_ipv4"])
+	}
+}
+
+func TestWaitForVMReady_failed(t *testing.T) {
+	api := newFakeAPI(t)
+	cfg := api.config()
+	api.slowProvisioning(1, "error")
+
+	_, diags := testResourceApply(t, resourceVirtualMachine(), nil, testVMRawConfig(nil), cfg)
+	if !diags.HasError() || !strings.Contains(fmt.Sprint(diags), `provisioning ended in status "error"`) {
+		t.Fatalf("got %v, want the failed provisioning reported", diags)
+	}
+}
+
+func TestWaitForVMReady_timeout(t *testing.T) {
+	api := newFakeAPI(t)
+	cfg := api.config()
+	api.slowProvisioning(1000000, vmStatusRunning)
+	c, err := cfg.Client("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.VM.Create(idcloudhostVM.NewVM{
+		Name: "web", OSName: "ubuntu", OSVersion: "20.04", Username: "example",
+		InitialPassword: "Password123", BillingAccount: fakeAPIBillingAccountID, Disks: 20, VCPU: 1, Memory: 1024,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Now()
+	if _, err := waitForVMReady(context.Background(), cfg, cfg.Region, c.VM.VM.UUID, 100*time.Millisecond); err == nil {
+		t.Fatal("waitForVMReady succeeded for a VM that never gets ready")
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("waitForVMReady took %s, want it bounded by the timeout", elapsed)
+	}
+}